@@ -0,0 +1,591 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andreymgn/RSOI-user/pkg/user"
+	"golang.org/x/crypto/bcrypt"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	envPrefix = "RSOI_USER_"
+	redacted  = "REDACTED"
+	// redisDatabases is a number of consecutive redis databases used by the service
+	redisDatabases = 5
+
+	defaultShutdownTimeout = 15 * time.Second
+
+	hashBcrypt   = "bcrypt"
+	hashArgon2id = "argon2id"
+
+	// methodPrefix is prepended to method names in rate_limits.methods to get full gRPC method names
+	methodPrefix = "/user.user/"
+)
+
+// lifetimesConfig describes token lifetimes
+type lifetimesConfig struct {
+	AccessToken  time.Duration `yaml:"access_token"`
+	RefreshToken time.Duration `yaml:"refresh_token"`
+	OAuthCode    time.Duration `yaml:"oauth_code"`
+	IDToken      time.Duration `yaml:"id_token"`
+	Session      time.Duration `yaml:"session"`
+}
+
+// lifetimes returns token lifetimes in format used by server
+func (l lifetimesConfig) lifetimes() user.TokenLifetimes {
+	return user.TokenLifetimes{
+		AccessToken:  l.AccessToken,
+		RefreshToken: l.RefreshToken,
+		OAuthCode:    l.OAuthCode,
+		IDToken:      l.IDToken,
+		Session:      l.Session,
+	}
+}
+
+// rateLimitConfig describes rate limit of a method
+type rateLimitConfig struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+	Key      string        `yaml:"key"`
+}
+
+// config describes settings of user service
+type config struct {
+	Port     int `yaml:"port"`
+	HTTPPort int `yaml:"http_port"`
+	// MetricsPort is a port of plaintext HTTP server exposing Prometheus metrics
+	MetricsPort int    `yaml:"metrics_port"`
+	DB          string `yaml:"db"`
+	JaegerAddr  string `yaml:"jaeger_addr"`
+	// ShutdownTimeout limits how long in-flight requests are waited for on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	Redis struct {
+		Addr     string `yaml:"addr"`
+		Password string `yaml:"password"`
+		DB       int    `yaml:"db"`
+	} `yaml:"redis"`
+
+	TLS struct {
+		CertFile     string `yaml:"cert_file"`
+		KeyFile      string `yaml:"key_file"`
+		ClientCAFile string `yaml:"client_ca_file"`
+		Insecure     bool   `yaml:"insecure"`
+	} `yaml:"tls"`
+
+	JWT struct {
+		KeysDir string `yaml:"keys_dir"`
+		Issuer  string `yaml:"issuer"`
+	} `yaml:"jwt"`
+
+	PasswordHash struct {
+		Algorithm  string `yaml:"algorithm"`
+		BcryptCost int    `yaml:"bcrypt_cost"`
+		// Argon2Memory is measured in KiB
+		Argon2Memory      int `yaml:"argon2_memory"`
+		Argon2Iterations  int `yaml:"argon2_iterations"`
+		Argon2Parallelism int `yaml:"argon2_parallelism"`
+	} `yaml:"password_hash"`
+
+	PasswordPolicy struct {
+		MinLength           int  `yaml:"min_length"`
+		MaxLength           int  `yaml:"max_length"`
+		MinCharacterClasses int  `yaml:"min_character_classes"`
+		DisallowUsername    bool `yaml:"disallow_username"`
+		DisallowCommon      bool `yaml:"disallow_common"`
+	} `yaml:"password_policy"`
+
+	LoginLimits struct {
+		UsernameAttempts  int           `yaml:"username_attempts"`
+		AddressAttempts   int           `yaml:"address_attempts"`
+		BaseDelay         time.Duration `yaml:"base_delay"`
+		MaxDelay          time.Duration `yaml:"max_delay"`
+		Window            time.Duration `yaml:"window"`
+		TrustForwardedFor bool          `yaml:"trust_forwarded_for"`
+	} `yaml:"login_limits"`
+
+	RateLimits struct {
+		// Shared keeps buckets in redis, so limits hold across replicas
+		Shared bool `yaml:"shared"`
+		// Methods maps method names to limits, a listed method replaces its default limit, zero requests disable it
+		Methods map[string]rateLimitConfig `yaml:"methods"`
+	} `yaml:"rate_limits"`
+
+	TokenLifetimes lifetimesConfig `yaml:"token_lifetimes"`
+	// GrantTokenLifetimes maps grants to lifetimes of tokens issued with them, zero fields are taken
+	// from TokenLifetimes
+	GrantTokenLifetimes map[string]lifetimesConfig `yaml:"grant_token_lifetimes"`
+}
+
+// defaultConfig returns config with default settings
+func defaultConfig() *config {
+	cfg := new(config)
+	cfg.ShutdownTimeout = defaultShutdownTimeout
+	cfg.TLS.CertFile = "/cert.pem"
+	cfg.TLS.KeyFile = "/key.pem"
+	cfg.PasswordHash.Algorithm = hashBcrypt
+	cfg.PasswordHash.BcryptCost = user.DefaultBcryptCost
+	cfg.PasswordHash.Argon2Memory = user.DefaultArgon2Memory
+	cfg.PasswordHash.Argon2Iterations = user.DefaultArgon2Iterations
+	cfg.PasswordHash.Argon2Parallelism = user.DefaultArgon2Parallelism
+	cfg.PasswordPolicy.MinLength = user.DefaultPasswordPolicy.MinLength
+	cfg.PasswordPolicy.MaxLength = user.DefaultPasswordPolicy.MaxLength
+	cfg.PasswordPolicy.MinCharacterClasses = user.DefaultPasswordPolicy.MinCharacterClasses
+	cfg.PasswordPolicy.DisallowUsername = user.DefaultPasswordPolicy.DisallowUsername
+	cfg.PasswordPolicy.DisallowCommon = user.DefaultPasswordPolicy.DisallowCommon
+	cfg.LoginLimits.UsernameAttempts = user.DefaultLoginLimits.UsernameAttempts
+	cfg.LoginLimits.AddressAttempts = user.DefaultLoginLimits.AddressAttempts
+	cfg.LoginLimits.BaseDelay = user.DefaultLoginLimits.BaseDelay
+	cfg.LoginLimits.MaxDelay = user.DefaultLoginLimits.MaxDelay
+	cfg.LoginLimits.Window = user.DefaultLoginLimits.Window
+	cfg.RateLimits.Methods = make(map[string]rateLimitConfig)
+	for method, limit := range user.DefaultRateLimits {
+		cfg.RateLimits.Methods[strings.TrimPrefix(method, methodPrefix)] = rateLimitConfig(limit)
+	}
+
+	cfg.TokenLifetimes.AccessToken = user.AccessTokenExpirationTime
+	cfg.TokenLifetimes.RefreshToken = user.RefreshTokenExpirationTime
+	cfg.TokenLifetimes.OAuthCode = user.OAuthCodeExpirationTime
+	cfg.TokenLifetimes.IDToken = user.IDTokenExpirationTime
+	cfg.TokenLifetimes.Session = user.MaxSessionTime
+	return cfg
+}
+
+// keyRetention returns how long retired signing keys are kept, it is the longest configured lifetime of signed
+// tokens. Tokens of apps with longer lifetimes are shortened to it, so they never outlive their keys
+func (cfg *config) keyRetention() time.Duration {
+	result := cfg.TokenLifetimes.AccessToken
+	if cfg.TokenLifetimes.IDToken > result {
+		result = cfg.TokenLifetimes.IDToken
+	}
+
+	for _, l := range cfg.GrantTokenLifetimes {
+		if l.AccessToken > result {
+			result = l.AccessToken
+		}
+
+		if l.IDToken > result {
+			result = l.IDToken
+		}
+	}
+
+	return result
+}
+
+// tlsConfig returns TLS settings in format used by server
+func (cfg *config) tlsConfig() user.TLSConfig {
+	return user.TLSConfig{
+		CertFile:     cfg.TLS.CertFile,
+		KeyFile:      cfg.TLS.KeyFile,
+		ClientCAFile: cfg.TLS.ClientCAFile,
+		Insecure:     cfg.TLS.Insecure,
+	}
+}
+
+// hasher returns hasher of new passwords, config must be valid
+func (cfg *config) hasher() user.PasswordHasher {
+	if cfg.PasswordHash.Algorithm == hashArgon2id {
+		return user.Argon2idHasher{
+			Memory:      uint32(cfg.PasswordHash.Argon2Memory),
+			Iterations:  uint32(cfg.PasswordHash.Argon2Iterations),
+			Parallelism: uint8(cfg.PasswordHash.Argon2Parallelism),
+		}
+	}
+
+	return user.BcryptHasher{Cost: cfg.PasswordHash.BcryptCost}
+}
+
+// passwordPolicy returns password policy in format used by server
+func (cfg *config) passwordPolicy() user.PasswordPolicy {
+	return user.PasswordPolicy{
+		MinLength:           cfg.PasswordPolicy.MinLength,
+		MaxLength:           cfg.PasswordPolicy.MaxLength,
+		MinCharacterClasses: cfg.PasswordPolicy.MinCharacterClasses,
+		DisallowUsername:    cfg.PasswordPolicy.DisallowUsername,
+		DisallowCommon:      cfg.PasswordPolicy.DisallowCommon,
+	}
+}
+
+// loginLimits returns login limits in format used by server
+func (cfg *config) loginLimits() user.LoginLimits {
+	return user.LoginLimits{
+		UsernameAttempts:  cfg.LoginLimits.UsernameAttempts,
+		AddressAttempts:   cfg.LoginLimits.AddressAttempts,
+		BaseDelay:         cfg.LoginLimits.BaseDelay,
+		MaxDelay:          cfg.LoginLimits.MaxDelay,
+		Window:            cfg.LoginLimits.Window,
+		TrustForwardedFor: cfg.LoginLimits.TrustForwardedFor,
+	}
+}
+
+// rateLimits returns rate limits in format used by server
+func (cfg *config) rateLimits() map[string]user.RateLimit {
+	result := make(map[string]user.RateLimit)
+	for method, limit := range cfg.RateLimits.Methods {
+		if limit.Requests > 0 {
+			result[methodPrefix+method] = user.RateLimit(limit)
+		}
+	}
+
+	return result
+}
+
+// legacyEnv maps flags to env vars used before config file was introduced
+var legacyEnv = map[string]string{
+	"port":           "PORT",
+	"db":             "CONN",
+	"jaeger-addr":    "JAEGER-ADDR",
+	"redis-addr":     "REDIS-ADDR",
+	"redis-password": "REDIS-PASS",
+	"redis-db":       "REDIS-DB",
+}
+
+// envName returns name of env var corresponding to flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// loadConfig builds config from defaults, config file, env vars and flags, each overriding the previous ones.
+// It returns config and whether effective config should be printed instead of running the service
+func loadConfig(args []string) (*config, bool, error) {
+	cfg := defaultConfig()
+	fs := flag.NewFlagSet("RSOI-user", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envName("config")), "path to YAML config file, env "+envName("config"))
+	printConfig := fs.Bool("print-config", false, "print effective config with secrets redacted and exit")
+
+	fs.IntVar(&cfg.Port, "port", cfg.Port, "gRPC port")
+	fs.IntVar(&cfg.HTTPPort, "http-port", cfg.HTTPPort, "OAuth 2.0 HTTP port, zero disables HTTP server")
+	fs.IntVar(&cfg.MetricsPort, "metrics-port", cfg.MetricsPort, "Prometheus metrics HTTP port, zero disables metrics server")
+	fs.StringVar(&cfg.DB, "db", cfg.DB, "PostgreSQL connection string")
+	fs.StringVar(&cfg.JaegerAddr, "jaeger-addr", cfg.JaegerAddr, "Jaeger agent address")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "how long to wait for in-flight requests on SIGINT or SIGTERM")
+	fs.StringVar(&cfg.Redis.Addr, "redis-addr", cfg.Redis.Addr, "Redis address")
+	fs.StringVar(&cfg.Redis.Password, "redis-password", cfg.Redis.Password, "Redis password")
+	fs.IntVar(&cfg.Redis.DB, "redis-db", cfg.Redis.DB, fmt.Sprintf("first of %d Redis databases used for tokens, failed login counters and rate limits", redisDatabases))
+	fs.StringVar(&cfg.TLS.CertFile, "tls-cert", cfg.TLS.CertFile, "TLS certificate file, reloaded when changed")
+	fs.StringVar(&cfg.TLS.KeyFile, "tls-key", cfg.TLS.KeyFile, "TLS private key file, reloaded when changed")
+	fs.StringVar(&cfg.TLS.ClientCAFile, "tls-client-ca", cfg.TLS.ClientCAFile, "CA certificates of clients allowed to call admin RPCs, admin RPCs are rejected when it is empty")
+	fs.BoolVar(&cfg.TLS.Insecure, "insecure", cfg.TLS.Insecure, "serve plaintext without TLS, for local development only")
+	fs.StringVar(&cfg.JWT.KeysDir, "jwt-keys-dir", cfg.JWT.KeysDir, "directory with JWT signing keys, empty disables JWT access tokens")
+	fs.StringVar(&cfg.JWT.Issuer, "jwt-issuer", cfg.JWT.Issuer, "issuer of JWT tokens and base url of OAuth 2.0 endpoints")
+	fs.StringVar(&cfg.PasswordHash.Algorithm, "password-hash", cfg.PasswordHash.Algorithm, "algorithm of new password hashes, bcrypt or argon2id, outdated hashes are replaced on login")
+	fs.IntVar(&cfg.PasswordHash.BcryptCost, "bcrypt-cost", cfg.PasswordHash.BcryptCost, "bcrypt cost")
+	fs.IntVar(&cfg.PasswordHash.Argon2Memory, "argon2-memory", cfg.PasswordHash.Argon2Memory, "argon2id memory in KiB")
+	fs.IntVar(&cfg.PasswordHash.Argon2Iterations, "argon2-iterations", cfg.PasswordHash.Argon2Iterations, "argon2id number of passes")
+	fs.IntVar(&cfg.PasswordHash.Argon2Parallelism, "argon2-parallelism", cfg.PasswordHash.Argon2Parallelism, "argon2id number of threads")
+	fs.IntVar(&cfg.PasswordPolicy.MinLength, "password-min-length", cfg.PasswordPolicy.MinLength, "minimal password length in characters")
+	fs.IntVar(&cfg.PasswordPolicy.MaxLength, "password-max-length", cfg.PasswordPolicy.MaxLength, fmt.Sprintf("maximal password length in bytes, at most %d with bcrypt", user.MaxBcryptPasswordLength))
+	fs.IntVar(&cfg.PasswordPolicy.MinCharacterClasses, "password-min-classes", cfg.PasswordPolicy.MinCharacterClasses, "number of classes out of lowercase, uppercase, digits and other characters password must contain")
+	fs.BoolVar(&cfg.PasswordPolicy.DisallowUsername, "password-disallow-username", cfg.PasswordPolicy.DisallowUsername, "reject passwords containing username")
+	fs.BoolVar(&cfg.PasswordPolicy.DisallowCommon, "password-disallow-common", cfg.PasswordPolicy.DisallowCommon, "reject commonly used passwords")
+	fs.IntVar(&cfg.LoginLimits.UsernameAttempts, "login-username-attempts", cfg.LoginLimits.UsernameAttempts, "failed logins of a username allowed before backoff starts")
+	fs.IntVar(&cfg.LoginLimits.AddressAttempts, "login-address-attempts", cfg.LoginLimits.AddressAttempts, "failed logins from a client address allowed before backoff starts")
+	fs.DurationVar(&cfg.LoginLimits.BaseDelay, "login-base-delay", cfg.LoginLimits.BaseDelay, "delay after the first failed login over the limit, doubled with every next failure")
+	fs.DurationVar(&cfg.LoginLimits.MaxDelay, "login-max-delay", cfg.LoginLimits.MaxDelay, "maximal delay between failed logins")
+	fs.DurationVar(&cfg.LoginLimits.Window, "login-window", cfg.LoginLimits.Window, "how long failed logins are remembered after the last one")
+	fs.BoolVar(&cfg.LoginLimits.TrustForwardedFor, "login-trust-forwarded-for", cfg.LoginLimits.TrustForwardedFor, "take client address from X-Forwarded-For, only behind a proxy which sets it")
+	fs.BoolVar(&cfg.RateLimits.Shared, "rate-limits-shared", cfg.RateLimits.Shared, "keep rate limit buckets in Redis to share them between replicas, limits of methods are set in config file")
+	fs.DurationVar(&cfg.TokenLifetimes.AccessToken, "access-token-ttl", cfg.TokenLifetimes.AccessToken, "access token lifetime")
+	fs.DurationVar(&cfg.TokenLifetimes.RefreshToken, "refresh-token-ttl", cfg.TokenLifetimes.RefreshToken, "refresh token lifetime")
+	fs.DurationVar(&cfg.TokenLifetimes.OAuthCode, "oauth-code-ttl", cfg.TokenLifetimes.OAuthCode, "oauth code lifetime")
+	fs.DurationVar(&cfg.TokenLifetimes.IDToken, "id-token-ttl", cfg.TokenLifetimes.IDToken, "OpenID Connect ID token lifetime")
+	fs.DurationVar(&cfg.TokenLifetimes.Session, "session-ttl", cfg.TokenLifetimes.Session, "maximum session lifetime, tokens can not be refreshed or extended beyond it")
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name != "config" && f.Name != "print-config" {
+			f.Usage += ", env " + envName(f.Name)
+		}
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	// flags were written to config while parsing, remember them to apply after file and env vars
+	flags := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	*cfg = *defaultConfig()
+	if *configPath != "" {
+		if err := cfg.readFile(*configPath); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var errs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "print-config" {
+			return
+		}
+
+		name := envName(f.Name)
+		value, ok := os.LookupEnv(name)
+		if legacy, found := legacyEnv[f.Name]; !ok && found {
+			name = legacy
+			value, ok = os.LookupEnv(name)
+		}
+
+		if ok {
+			if err := f.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Sprintf("env %s: invalid value %q", name, value))
+			}
+		}
+	})
+
+	if len(errs) > 0 {
+		return nil, false, errors.New(strings.Join(errs, "; "))
+	}
+
+	for name, value := range flags {
+		fs.Set(name, value)
+	}
+
+	return cfg, *printConfig, nil
+}
+
+// readFile reads settings from YAML file, fields missing in file keep their values
+func (cfg *config) readFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %v", err)
+	}
+
+	// strict unmarshaling rejects keys which are already in map, so default rate limits are merged afterwards
+	defaultRateLimits := cfg.RateLimits.Methods
+	cfg.RateLimits.Methods = nil
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+
+	if cfg.RateLimits.Methods == nil {
+		cfg.RateLimits.Methods = make(map[string]rateLimitConfig)
+	}
+
+	for method, limit := range defaultRateLimits {
+		if _, ok := cfg.RateLimits.Methods[method]; !ok {
+			cfg.RateLimits.Methods[method] = limit
+		}
+	}
+
+	return nil
+}
+
+// validate checks config and returns error listing all invalid settings
+func (cfg *config) validate() error {
+	var errs []string
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		errs = append(errs, "port must be between 1 and 65535")
+	}
+
+	if cfg.HTTPPort < 0 || cfg.HTTPPort > 65535 {
+		errs = append(errs, "http_port must be between 0 and 65535")
+	} else if cfg.HTTPPort != 0 && cfg.HTTPPort == cfg.Port {
+		errs = append(errs, "http_port must differ from port")
+	}
+
+	if cfg.MetricsPort < 0 || cfg.MetricsPort > 65535 {
+		errs = append(errs, "metrics_port must be between 0 and 65535")
+	} else if cfg.MetricsPort != 0 && (cfg.MetricsPort == cfg.Port || cfg.MetricsPort == cfg.HTTPPort) {
+		errs = append(errs, "metrics_port must differ from port and http_port")
+	}
+
+	if cfg.DB == "" {
+		errs = append(errs, "db is required")
+	}
+
+	if cfg.ShutdownTimeout <= 0 {
+		errs = append(errs, "shutdown_timeout must be positive")
+	}
+
+	if cfg.Redis.Addr == "" {
+		errs = append(errs, "redis.addr is required")
+	}
+
+	if cfg.Redis.DB < 0 {
+		errs = append(errs, "redis.db must not be negative")
+	}
+
+	if !cfg.TLS.Insecure && (cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "") {
+		errs = append(errs, "tls.cert_file and tls.key_file are required unless tls.insecure is set")
+	}
+
+	if cfg.TLS.Insecure && cfg.TLS.ClientCAFile != "" {
+		errs = append(errs, "tls.client_ca_file can not be used with tls.insecure")
+	}
+
+	if cfg.JWT.Issuer != "" {
+		if u, err := url.Parse(cfg.JWT.Issuer); err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+			errs = append(errs, "jwt.issuer must be an http or https url")
+		}
+	}
+
+	switch cfg.PasswordHash.Algorithm {
+	case hashBcrypt:
+		if cfg.PasswordHash.BcryptCost < bcrypt.MinCost || cfg.PasswordHash.BcryptCost > bcrypt.MaxCost {
+			errs = append(errs, fmt.Sprintf("password_hash.bcrypt_cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+		}
+	case hashArgon2id:
+		if cfg.PasswordHash.Argon2Parallelism < 1 || cfg.PasswordHash.Argon2Parallelism > 255 {
+			errs = append(errs, "password_hash.argon2_parallelism must be between 1 and 255")
+		}
+
+		// argon2 requires at least 8 KiB of memory per thread
+		if cfg.PasswordHash.Argon2Memory < 8*cfg.PasswordHash.Argon2Parallelism || cfg.PasswordHash.Argon2Memory > user.MaxArgon2Memory {
+			errs = append(errs, fmt.Sprintf("password_hash.argon2_memory must be at least 8 KiB per thread and at most %d KiB", user.MaxArgon2Memory))
+		}
+
+		if cfg.PasswordHash.Argon2Iterations < 1 || cfg.PasswordHash.Argon2Iterations > user.MaxArgon2Iterations {
+			errs = append(errs, fmt.Sprintf("password_hash.argon2_iterations must be between 1 and %d", user.MaxArgon2Iterations))
+		}
+	default:
+		errs = append(errs, "password_hash.algorithm must be bcrypt or argon2id")
+	}
+
+	policy := cfg.PasswordPolicy
+	if policy.MinLength < 0 {
+		errs = append(errs, "password_policy.min_length must not be negative")
+	}
+
+	if policy.MaxLength < 0 || policy.MaxLength > 0 && policy.MaxLength < policy.MinLength {
+		errs = append(errs, "password_policy.max_length must be zero or not less than min_length")
+	}
+
+	// bcrypt silently ignores the rest of password, so longer passwords give false sense of security
+	if cfg.PasswordHash.Algorithm == hashBcrypt && (policy.MaxLength == 0 || policy.MaxLength > user.MaxBcryptPasswordLength) {
+		errs = append(errs, fmt.Sprintf("password_policy.max_length must be between 1 and %d with bcrypt", user.MaxBcryptPasswordLength))
+	}
+
+	if policy.MinCharacterClasses < 0 || policy.MinCharacterClasses > 4 {
+		errs = append(errs, "password_policy.min_character_classes must be between 0 and 4")
+	}
+
+	limits := cfg.LoginLimits
+	if limits.UsernameAttempts < 0 || limits.AddressAttempts < 0 {
+		errs = append(errs, "login_limits.username_attempts and login_limits.address_attempts must not be negative")
+	}
+
+	if limits.BaseDelay <= 0 || limits.MaxDelay < limits.BaseDelay {
+		errs = append(errs, "login_limits.base_delay must be positive and not greater than login_limits.max_delay")
+	}
+
+	if limits.Window <= 0 {
+		errs = append(errs, "login_limits.window must be positive")
+	}
+
+	methods := make([]string, 0, len(cfg.RateLimits.Methods))
+	for method := range cfg.RateLimits.Methods {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+	for _, method := range methods {
+		limit := cfg.RateLimits.Methods[method]
+		if limit.Requests < 0 || limit.Burst < 0 {
+			errs = append(errs, fmt.Sprintf("rate_limits.methods.%s: requests and burst must not be negative", method))
+		}
+
+		if limit.Requests > 0 && limit.Period <= 0 {
+			errs = append(errs, fmt.Sprintf("rate_limits.methods.%s: period must be positive", method))
+		}
+
+		switch limit.Key {
+		case user.RateLimitByPeer, user.RateLimitByApp, user.RateLimitByUser:
+		default:
+			if limit.Requests > 0 {
+				errs = append(errs, fmt.Sprintf("rate_limits.methods.%s: key must be peer, app or user", method))
+			}
+		}
+	}
+
+	lifetimes := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"token_lifetimes.access_token", cfg.TokenLifetimes.AccessToken},
+		{"token_lifetimes.refresh_token", cfg.TokenLifetimes.RefreshToken},
+		{"token_lifetimes.oauth_code", cfg.TokenLifetimes.OAuthCode},
+		{"token_lifetimes.id_token", cfg.TokenLifetimes.IDToken},
+		// zero lifetimes mean inherited ones for the server, so session can not be made unlimited
+		{"token_lifetimes.session", cfg.TokenLifetimes.Session},
+	}
+	for _, l := range lifetimes {
+		if l.value <= 0 {
+			errs = append(errs, l.name+" must be positive")
+		}
+	}
+
+	grants := make([]string, 0, len(cfg.GrantTokenLifetimes))
+	for grant := range cfg.GrantTokenLifetimes {
+		grants = append(grants, grant)
+	}
+
+	sort.Strings(grants)
+	for _, grant := range grants {
+		switch grant {
+		case user.GrantPassword, user.GrantAuthorizationCode, user.GrantClientCredentials:
+		default:
+			errs = append(errs, fmt.Sprintf("grant_token_lifetimes: unknown grant %s, expected %s, %s or %s", grant, user.GrantPassword, user.GrantAuthorizationCode, user.GrantClientCredentials))
+			continue
+		}
+
+		l := cfg.GrantTokenLifetimes[grant]
+		if l.AccessToken < 0 || l.RefreshToken < 0 || l.OAuthCode < 0 || l.IDToken < 0 || l.Session < 0 {
+			errs = append(errs, fmt.Sprintf("grant_token_lifetimes.%s: lifetimes must not be negative", grant))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+var connPasswordRegexp = regexp.MustCompile(`password=('(\\.|[^'])*'|\S*)`)
+
+// redactConnString hides password in PostgreSQL connection string given as url or as key-value pairs
+func redactConnString(conn string) string {
+	if u, err := url.Parse(conn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+
+		query := u.Query()
+		if query.Get("password") != "" {
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+
+		return u.String()
+	}
+
+	return connPasswordRegexp.ReplaceAllString(conn, "password="+redacted)
+}
+
+// redacted returns YAML representation of config with secrets hidden
+func (cfg *config) redacted() ([]byte, error) {
+	result := *cfg
+	result.DB = redactConnString(cfg.DB)
+	if result.Redis.Password != "" {
+		result.Redis.Password = redacted
+	}
+
+	return yaml.Marshal(&result)
+}
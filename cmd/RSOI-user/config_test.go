@@ -0,0 +1,172 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setEnv sets env vars and returns function restoring previous values
+func setEnv(vars map[string]string) func() {
+	previous := make(map[string]*string)
+	for name, value := range vars {
+		if old, ok := os.LookupEnv(name); ok {
+			previous[name] = &old
+		} else {
+			previous[name] = nil
+		}
+
+		os.Setenv(name, value)
+	}
+
+	return func() {
+		for name, old := range previous {
+			if old == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *old)
+			}
+		}
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, "config.yaml")
+	config := "port: 1000\nhttp_port: 1001\nshutdown_timeout: 5s\n"
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		env               map[string]string
+		args              []string
+		wantPort          int
+		wantHTTPPort      int
+		wantShutdown      time.Duration
+		wantRedisPassword string
+	}{
+		{
+			name:         "defaults",
+			wantShutdown: defaultShutdownTimeout,
+		},
+		{
+			name:         "file overrides defaults",
+			args:         []string{"-config", configPath},
+			wantPort:     1000,
+			wantHTTPPort: 1001,
+			wantShutdown: 5 * time.Second,
+		},
+		{
+			name:         "config path from env",
+			env:          map[string]string{"RSOI_USER_CONFIG": configPath},
+			wantPort:     1000,
+			wantHTTPPort: 1001,
+			wantShutdown: 5 * time.Second,
+		},
+		{
+			name:         "env overrides file",
+			env:          map[string]string{"RSOI_USER_PORT": "2000"},
+			args:         []string{"-config", configPath},
+			wantPort:     2000,
+			wantHTTPPort: 1001,
+			wantShutdown: 5 * time.Second,
+		},
+		{
+			name:         "flag overrides env",
+			env:          map[string]string{"RSOI_USER_PORT": "2000", "RSOI_USER_HTTP_PORT": "2001"},
+			args:         []string{"-config", configPath, "-port", "3000"},
+			wantPort:     3000,
+			wantHTTPPort: 2001,
+			wantShutdown: 5 * time.Second,
+		},
+		{
+			name:              "legacy env",
+			env:               map[string]string{"PORT": "4000", "REDIS-PASS": "secret"},
+			args:              []string{"-config", configPath},
+			wantPort:          4000,
+			wantHTTPPort:      1001,
+			wantShutdown:      5 * time.Second,
+			wantRedisPassword: "secret",
+		},
+		{
+			name:         "env overrides legacy env",
+			env:          map[string]string{"PORT": "4000", "RSOI_USER_PORT": "5000"},
+			wantPort:     5000,
+			wantShutdown: defaultShutdownTimeout,
+		},
+		{
+			name:         "legacy names are read only for baseline settings",
+			env:          map[string]string{"HTTP-PORT": "6000"},
+			wantShutdown: defaultShutdownTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := setEnv(tt.env)
+			defer restore()
+
+			cfg, _, err := loadConfig(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Port != tt.wantPort {
+				t.Errorf("port = %d, want %d", cfg.Port, tt.wantPort)
+			}
+
+			if cfg.HTTPPort != tt.wantHTTPPort {
+				t.Errorf("http port = %d, want %d", cfg.HTTPPort, tt.wantHTTPPort)
+			}
+
+			if cfg.ShutdownTimeout != tt.wantShutdown {
+				t.Errorf("shutdown timeout = %v, want %v", cfg.ShutdownTimeout, tt.wantShutdown)
+			}
+
+			if cfg.Redis.Password != tt.wantRedisPassword {
+				t.Errorf("redis password = %q, want %q", cfg.Redis.Password, tt.wantRedisPassword)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalidEnv(t *testing.T) {
+	restore := setEnv(map[string]string{"RSOI_USER_PORT": "port"})
+	defer restore()
+
+	if _, _, err := loadConfig(nil); err == nil {
+		t.Error("loadConfig() accepted invalid env value")
+	}
+}
+
+func TestValidateSessionLifetime(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"720h", true},
+		{"0", false},
+		{"-1h", false},
+	}
+
+	for _, tt := range tests {
+		cfg, _, err := loadConfig([]string{"-port", "8080", "-db", "postgres://localhost/user", "-redis-addr", "localhost:6379", "-session-ttl", tt.value})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := cfg.validate(); (err == nil) != tt.valid {
+			t.Errorf("validate() with session lifetime %s = %v, want valid = %v", tt.value, err, tt.valid)
+		}
+	}
+}
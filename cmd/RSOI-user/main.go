@@ -1,31 +1,45 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
-	"strconv"
 )
 
 func main() {
-	conn := os.Getenv("CONN")
-	port, err := strconv.Atoi(os.Getenv("PORT"))
-	if err != nil {
-		log.Println("PORT parse error")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err == flag.ErrHelp {
+			return
+		} else if err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+
 		return
 	}
 
-	redisAddr := os.Getenv("REDIS-ADDR")
-	redisPass := os.Getenv("REDIS-PASS")
-	redisDB, err := strconv.Atoi(os.Getenv("REDIS-DB"))
-	if err != nil {
-		log.Println("REDIS-DB parse error")
+	cfg, printConfig, err := loadConfig(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		log.Fatalf("config error: %v", err)
+	}
+
+	if printConfig {
+		out, err := cfg.redacted()
+		if err != nil {
+			log.Fatalf("config error: %v", err)
+		}
+
+		os.Stdout.Write(out)
 		return
 	}
 
-	jaegerAddr := os.Getenv("JAEGER-ADDR")
+	if err := cfg.validate(); err != nil {
+		log.Fatal(err)
+	}
 
-	log.Printf("running user service on port %d\n", port)
-	err = runUser(port, conn, redisAddr, redisPass, redisDB, jaegerAddr)
+	log.Printf("running user service on port %d\n", cfg.Port)
+	err = runUser(cfg)
 
 	if err != nil {
 		log.Printf("finished with error %v", err)
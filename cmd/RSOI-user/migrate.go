@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreymgn/RSOI-user/pkg/user"
+)
+
+const migrateUsage = "usage: RSOI-user migrate up|down|status [flags]"
+
+// runMigrate runs migrate subcommand, config is read the same way as for the service but only db is used
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	command := args[0]
+	if command != "up" && command != "down" && command != "status" {
+		return errors.New(migrateUsage)
+	}
+
+	cfg, _, err := loadConfig(args[1:])
+	if err != nil {
+		return err
+	}
+
+	if cfg.DB == "" {
+		return errors.New("invalid config: db is required")
+	}
+
+	migrator, err := user.NewMigrator(cfg.DB)
+	if err != nil {
+		return err
+	}
+
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %d %s\n", m.Version, m.Name)
+		}
+
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+		return err
+	case "down":
+		reverted, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("reverted %d %s\n", reverted.Version, reverted.Name)
+		return nil
+	default:
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, m := range status {
+			appliedAt := "pending"
+			if !m.AppliedAt.IsZero() {
+				appliedAt = m.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, appliedAt)
+		}
+
+		return w.Flush()
+	}
+}
@@ -1,22 +1,118 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/andreymgn/RSOI-user/pkg/user"
 	"github.com/andreymgn/RSOI/pkg/tracer"
 )
 
-func runUser(port int, connString, redisAddr, redisPassword string, redisDB int, jaegerAddr string) error {
-	tracer, closer, err := tracer.NewTracer("user", jaegerAddr)
+func runUser(cfg *config) error {
+	tracer, closer, err := tracer.NewTracer("user", cfg.JaegerAddr)
 	if err != nil {
 		return err
 	}
 
 	defer closer.Close()
 
-	server, err := user.NewServer(connString, redisAddr, redisPassword, redisDB)
+	accessTokenStore, err := user.NewRedisTokenStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		return err
+	}
+
+	refreshTokenStore, err := user.NewRedisTokenStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB+1)
+	if err != nil {
+		return err
+	}
+
+	oauthCodeStore, err := user.NewRedisTokenStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB+2)
+	if err != nil {
+		return err
+	}
+
+	loginAttemptStore, err := user.NewRedisTokenStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB+3)
+	if err != nil {
+		return err
+	}
+
+	options := []user.Option{
+		user.WithAccessTokenStore(accessTokenStore),
+		user.WithRefreshTokenStore(refreshTokenStore),
+		user.WithOAuthCodeStore(oauthCodeStore),
+		user.WithLoginAttemptStore(loginAttemptStore),
+		user.WithTokenLifetimes(cfg.TokenLifetimes.lifetimes()),
+		user.WithTLS(cfg.tlsConfig()),
+		user.WithPasswordHasher(cfg.hasher()),
+		user.WithPasswordPolicy(cfg.passwordPolicy()),
+		user.WithLoginLimits(cfg.loginLimits()),
+		user.WithRateLimits(cfg.rateLimits()),
+	}
+
+	for grant, l := range cfg.GrantTokenLifetimes {
+		options = append(options, user.WithGrantTokenLifetimes(grant, l.lifetimes()))
+	}
+
+	if cfg.RateLimits.Shared {
+		rateLimiter, err := user.NewRedisRateLimiter(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB+4)
+		if err != nil {
+			return err
+		}
+
+		options = append(options, user.WithRateLimiter(rateLimiter))
+	}
+
+	if cfg.JWT.KeysDir != "" {
+		keys, err := user.NewKeyManager(cfg.JWT.KeysDir, cfg.keyRetention())
+		if err != nil {
+			return err
+		}
+
+		options = append(options, user.WithJWTSigner(user.NewJWTSigner(keys, cfg.JWT.Issuer)))
+	}
+
+	server, err := user.NewServer(cfg.DB, options...)
 	if err != nil {
 		return err
 	}
 
-	return server.Start(port, tracer)
+	errc := make(chan error, 3)
+	if cfg.HTTPPort != 0 {
+		go func() {
+			errc <- server.StartHTTP(cfg.HTTPPort, cfg.JWT.Issuer)
+		}()
+	}
+
+	if cfg.MetricsPort != 0 {
+		go func() {
+			errc <- server.StartMetrics(cfg.MetricsPort)
+		}()
+	}
+
+	go func() {
+		errc <- server.Start(cfg.Port, tracer)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err = <-errc:
+	case sig := <-signals:
+		log.Printf("received %v, shutting down", sig)
+	}
+
+	// other servers may still be running when one of them failed, so stop all of them in any case
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if stopErr := server.Stop(ctx); err == nil {
+		err = stopErr
+	}
+
+	return err
 }
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	statusInvalidCredentials = status.Error(codes.Unauthenticated, "invalid username or password")
+)
+
+// authenticate checks username and password and returns uid of user. Unknown usernames and wrong passwords get
+// the same statusInvalidCredentials after a password comparison of the same cost, so neither response nor its
+// timing reveals whether account exists. Stored hashes made with another algorithm or cheaper parameters take
+// different time to compare until they are rehashed on successful login, timing of such accounts still differs
+func (s *Server) authenticate(ctx context.Context, username, password string) (uuid.UUID, error) {
+	attempt, err := s.startLogin(ctx, username)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	uid, err := s.db.getUIDByUsername(username)
+	if err == errNotFound {
+		verifyPassword(password, s.dummyHash)
+		logins.WithLabelValues(loginUnknownUser).Inc()
+		attempt.failed()
+		return uuid.Nil, statusInvalidCredentials
+	} else if err != nil {
+		return uuid.Nil, internalError(err)
+	}
+
+	samePassword, err := s.checkPassword(uid, password)
+	if err == errNotFound {
+		// user was deleted after lookup
+		logins.WithLabelValues(loginUnknownUser).Inc()
+		attempt.failed()
+		return uuid.Nil, statusInvalidCredentials
+	} else if err == errUnknownHashFormat {
+		// nobody can log in with corrupted hash, but it is not a reason to tell client that account exists
+		log.Printf("password hash of user %s has unknown format", uid)
+		verifyPassword(password, s.dummyHash)
+		logins.WithLabelValues(loginWrongPassword).Inc()
+		attempt.failed()
+		return uuid.Nil, statusInvalidCredentials
+	} else if err != nil {
+		return uuid.Nil, internalError(err)
+	}
+
+	if !samePassword {
+		logins.WithLabelValues(loginWrongPassword).Inc()
+		attempt.failed()
+		return uuid.Nil, statusInvalidCredentials
+	}
+
+	logins.WithLabelValues(loginSuccess).Inc()
+	attempt.succeeded()
+	return uid, nil
+}
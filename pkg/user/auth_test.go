@@ -0,0 +1,77 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticate(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "alice", "correct horse", false)
+	corrupted := addUser(t, s, db, "bob", "correct horse", false)
+	db.users[corrupted].passwordHash = "not a hash"
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     uuid.UUID
+		wantErr  error
+	}{
+		{"correct password", "alice", "correct horse", uid, nil},
+		{"username in another case", "ALICE", "correct horse", uid, nil},
+		{"wrong password", "alice", "wrong horse", uuid.Nil, statusInvalidCredentials},
+		{"empty password", "alice", "", uuid.Nil, statusInvalidCredentials},
+		{"unknown user", "mallory", "correct horse", uuid.Nil, statusInvalidCredentials},
+		{"invalid username", "not a username", "correct horse", uuid.Nil, statusInvalidCredentials},
+		{"unknown hash format", "bob", "correct horse", uuid.Nil, statusInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.authenticate(context.Background(), tt.username, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("authenticate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDatabaseError(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	addUser(t, s, db, "alice", "correct horse", false)
+	db.err = errFakeDB
+
+	_, err := s.authenticate(context.Background(), "alice", "correct horse")
+	if status.Code(err) != codes.Internal {
+		t.Errorf("authenticate() error = %v, want internal error", err)
+	}
+}
+
+func TestAuthenticateLegacyUsername(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	// users registered before usernames were normalized are matched by exact name only
+	legacy := addUser(t, s, db, "carol", "correct horse", false)
+	db.users[legacy].Username = "Carol Smith"
+	db.users[legacy].normalized = ""
+
+	got, err := s.authenticate(context.Background(), "Carol Smith", "correct horse")
+	if err != nil || got != legacy {
+		t.Errorf("authenticate() = %v, %v, want %v", got, err, legacy)
+	}
+
+	if _, err := s.authenticate(context.Background(), "carol smith", "correct horse"); err != statusInvalidCredentials {
+		t.Errorf("authenticate() with legacy username in another case: got %v, want %v", err, statusInvalidCredentials)
+	}
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// SecurityEventRefreshTokenReuse is emitted when already rotated refresh token is presented again
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+)
+
+// SecurityEvent describes suspicious activity detected by server
+type SecurityEvent struct {
+	Type   string
+	UID    string
+	Detail string
+	Time   time.Time
+}
+
+// SecurityEventHandler receives security events
+type SecurityEventHandler func(SecurityEvent)
+
+func logSecurityEvent(event SecurityEvent) {
+	log.Printf("security event %s: uid %s, %s", event.Type, event.UID, event.Detail)
+}
+
+func (s *Server) emitSecurityEvent(eventType, uid, detail string) {
+	s.securityEventHandler(SecurityEvent{
+		Type:   eventType,
+		UID:    uid,
+		Detail: detail,
+		Time:   time.Now(),
+	})
+}
@@ -0,0 +1,251 @@
+package user
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthCheckInterval = 10 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+
+	// healthServiceName is a name under which user service status is reported, empty name reports whole server
+	healthServiceName = "user.user"
+
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// HealthChecker is implemented by dependencies which can report whether they are reachable
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// readinessResponse lists dependencies which failed the last health check
+type readinessResponse struct {
+	Ready  bool              `json:"ready"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// healthState keeps results of the last health check
+type healthState struct {
+	server *health.Server
+
+	startOnce sync.Once
+	done      chan struct{}
+
+	mu     sync.Mutex
+	errors map[string]string
+}
+
+func newHealthState() *healthState {
+	h := &healthState{
+		server: health.NewServer(),
+		done:   make(chan struct{}),
+		// not ready until dependencies are checked for the first time
+		errors: map[string]string{"health": "not checked yet"},
+	}
+
+	h.setServing(false)
+	return h
+}
+
+func (h *healthState) setServing(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	h.server.SetServingStatus("", status)
+	h.server.SetServingStatus(healthServiceName, status)
+}
+
+// set records results of health check unless server is already stopping
+func (h *healthState) set(errors map[string]string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+
+	h.errors = errors
+	h.setServing(len(errors) == 0)
+}
+
+func (h *healthState) get() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.errors
+}
+
+// stop stops health checks and reports server as not serving so that clients move to other instances
+func (h *healthState) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return
+	default:
+		close(h.done)
+	}
+
+	h.errors = map[string]string{"health": "server is stopping"}
+	h.setServing(false)
+}
+
+// dependencies returns dependencies of server which can be checked
+func (s *Server) dependencies() map[string]HealthChecker {
+	result := make(map[string]HealthChecker)
+	resources := map[string]interface{}{
+		"db":                  s.db,
+		"access_token_store":  s.accessTokenStorage,
+		"refresh_token_store": s.refreshTokenStorage,
+		"oauth_code_store":    s.oauthCodeStorage,
+		"login_attempt_store": s.loginAttemptStorage,
+		"rate_limiter":        s.rateLimiter,
+	}
+	for name, resource := range resources {
+		if checker, ok := resource.(HealthChecker); ok {
+			result[name] = checker
+		}
+	}
+
+	return result
+}
+
+// checkHealth checks all dependencies concurrently and returns errors of unreachable ones
+func (s *Server) checkHealth() map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errors := make(map[string]string)
+	for name, checker := range s.dependencies() {
+		wg.Add(1)
+		go func(name string, checker HealthChecker) {
+			defer wg.Done()
+			if err := checker.CheckHealth(ctx); err != nil {
+				mu.Lock()
+				errors[name] = err.Error()
+				mu.Unlock()
+			}
+		}(name, checker)
+	}
+
+	wg.Wait()
+	return errors
+}
+
+// startHealthChecks starts background health checks once, they run until server is stopped
+func (s *Server) startHealthChecks() {
+	s.health.startOnce.Do(func() {
+		go s.runHealthChecks()
+	})
+}
+
+func (s *Server) runHealthChecks() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	var failed []string
+	for {
+		errors := s.checkHealth()
+
+		// log only changes of failed dependencies to avoid flooding log every interval
+		names := make([]string, 0, len(errors))
+		for name := range errors {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		if !equalStrings(names, failed) {
+			if len(names) == 0 {
+				log.Printf("health check: all dependencies are reachable")
+			} else {
+				log.Printf("health check: unreachable dependencies %v", errors)
+			}
+		}
+
+		failed = names
+		s.health.set(errors)
+
+		select {
+		case <-s.health.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// healthz reports that process is alive
+func (h *httpServer) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// readyz reports whether all dependencies were reachable during the last health check
+func (h *httpServer) readyz(w http.ResponseWriter, r *http.Request) {
+	errors := h.health.get()
+	code := http.StatusOK
+	if len(errors) > 0 {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, &readinessResponse{len(errors) == 0, errors})
+}
+
+func (db *db) CheckHealth(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+func (s *redisTokenStore) CheckHealth(ctx context.Context) error {
+	return pingRedis(ctx, s.Client)
+}
+
+func (l *redisRateLimiter) CheckHealth(ctx context.Context) error {
+	return pingRedis(ctx, l.Client)
+}
+
+// pingRedis pings redis until ctx is done, commands of this redis client can not be cancelled, so ping is left
+// to finish in background after that
+func pingRedis(ctx context.Context, client *redis.Client) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- client.Ping().Err()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,737 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pb "github.com/andreymgn/RSOI-user/pkg/user/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizePath    = "/authorize"
+	tokenPath        = "/token"
+	revokePath       = "/revoke"
+	introspectPath   = "/introspect"
+	jwksPath         = "/jwks"
+	userInfoPath     = "/userinfo"
+	openIDConfigPath = "/.well-known/openid-configuration"
+)
+
+const (
+	grantTypeRefresh             = "refresh_token"
+	responseTypeCode             = "code"
+	tokenTypeBearer              = "Bearer"
+	formContentType              = "application/x-www-form-urlencoded"
+	jsonContentType              = "application/json"
+	oauthErrorRequest            = "invalid_request"
+	oauthErrorClient             = "invalid_client"
+	oauthErrorUnauthorizedClient = "unauthorized_client"
+	oauthErrorGrant              = "invalid_grant"
+	oauthErrorScope              = "invalid_scope"
+	oauthErrorDenied             = "access_denied"
+	oauthErrorServer             = "server_error"
+	oauthErrorBusy               = "temporarily_unavailable"
+)
+
+// oauthError is an error response described in RFC 6749 section 5.2
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// tokenResponse is a successful token response described in RFC 6749 section 5.1
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// introspectionResponse is a token introspection response described in RFC 7662 section 2.2
+type introspectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	// SubjectType tells whether subject is user or app
+	SubjectType string `json:"subject_type,omitempty"`
+}
+
+// userInfoResponse is a user info response described in OpenID Connect Core 1.0 section 5.3.2
+type userInfoResponse struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+// providerMetadata is a discovery document described in OpenID Connect Discovery 1.0 section 3
+type providerMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// httpServer exposes OAuth 2.0 endpoints of server over HTTP
+type httpServer struct {
+	*Server
+	// issuer is a base url of endpoints, when empty it is derived from request
+	issuer string
+	// interceptor applies the same metrics and rate limits to methods called over HTTP as to gRPC requests
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// HTTPHandler returns handler of OAuth 2.0 and OpenID Connect discovery endpoints along with /healthz and /readyz
+// probes, issuer is used as base url of endpoints in discovery document
+func (s *Server) HTTPHandler(issuer string) http.Handler {
+	h := &httpServer{s, strings.TrimSuffix(issuer, "/"), chainUnaryInterceptors(observeRPC, s.limitRate)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(authorizePath, h.authorize)
+	mux.HandleFunc(tokenPath, h.token)
+	mux.HandleFunc(revokePath, h.revoke)
+	mux.HandleFunc(introspectPath, h.introspect)
+	mux.HandleFunc(jwksPath, h.jwks)
+	mux.HandleFunc(userInfoPath, h.userInfo)
+	mux.HandleFunc(openIDConfigPath, h.openIDConfiguration)
+	mux.HandleFunc(healthzPath, h.healthz)
+	mux.HandleFunc(readyzPath, h.readyz)
+	return mux
+}
+
+// StartHTTP starts HTTP server with OAuth 2.0 endpoints, it uses the same TLS settings as gRPC server
+// except that client certificates are not requested. It returns nil after server is stopped
+func (s *Server) StartHTTP(port int, issuer string) error {
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: s.HTTPHandler(issuer),
+	}
+
+	if !s.tls.Insecure {
+		certs, err := newCertReloader(s.tls)
+		if err != nil {
+			return err
+		}
+
+		server.TLSConfig = certs.serverConfig([]string{"h2", "http/1.1"}, false)
+	}
+
+	if !s.addHTTPServer(server) {
+		return errServerStopped
+	}
+
+	lis, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.startHealthChecks()
+	if s.tls.Insecure {
+		err = server.Serve(lis)
+	} else {
+		err = server.ServeTLS(lis, "", "")
+	}
+
+	// ErrServerClosed is returned after server is stopped
+	if err == http.ErrServerClosed {
+		return nil
+	}
+
+	s.health.stop()
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeOAuthError(w http.ResponseWriter, code int, errorCode, description string) {
+	if code == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	writeJSON(w, code, &oauthError{errorCode, description})
+}
+
+// parsePostForm parses form-encoded body of POST request, it writes error response and returns false on failure
+func parsePostForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOAuthError(w, http.StatusMethodNotAllowed, oauthErrorRequest, "method must be POST")
+		return false
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), formContentType) {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "content type must be "+formContentType)
+		return false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "malformed request body")
+		return false
+	}
+
+	return true
+}
+
+// clientCredentials returns client id and secret sent with HTTP basic authentication or in request body
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		return id, secret
+	}
+
+	return r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+}
+
+// clientContext returns request context carrying client address the same way gRPC requests do,
+// so logins over HTTP are throttled per address too
+func clientContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+
+	if forwardedFor := r.Header.Get(forwardedForKey); forwardedFor != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(forwardedForKey, forwardedFor))
+	}
+
+	return ctx
+}
+
+// httpTransportStream collects trailers set by interceptors and methods called over HTTP
+type httpTransportStream struct {
+	method  string
+	trailer metadata.MD
+}
+
+func (s *httpTransportStream) Method() string {
+	return s.method
+}
+
+func (s *httpTransportStream) SetHeader(md metadata.MD) error {
+	return nil
+}
+
+func (s *httpTransportStream) SendHeader(md metadata.MD) error {
+	return nil
+}
+
+func (s *httpTransportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+// invoke runs call as gRPC method with full name method, req is only used to find rate limit key.
+// Retry-After header is set from retry-after trailer when request is throttled
+func (h *httpServer) invoke(w http.ResponseWriter, r *http.Request, method string, req interface{}, call func(context.Context) error) error {
+	stream := &httpTransportStream{method: method}
+	ctx := grpc.NewContextWithServerTransportStream(clientContext(r), stream)
+	info := &grpc.UnaryServerInfo{Server: h.Server, FullMethod: method}
+	_, err := h.interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, call(ctx)
+	})
+
+	if retryAfter := stream.trailer.Get(retryAfterKey); len(retryAfter) > 0 {
+		w.Header().Set("Retry-After", retryAfter[len(retryAfter)-1])
+	}
+
+	return err
+}
+
+// baseURL returns issuer or url of host request was sent to
+func (h *httpServer) baseURL(r *http.Request) string {
+	if h.issuer != "" {
+		return h.issuer
+	}
+
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+// authorize handles authorization request described in RFC 6749 section 4.1.1,
+// resource owner credentials must be posted by login page of the frontend
+func (h *httpServer) authorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOAuthError(w, http.StatusMethodNotAllowed, oauthErrorRequest, "method must be POST")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "malformed request")
+		return
+	}
+
+	clientID := r.FormValue("client_id")
+	redirectURI := r.FormValue("redirect_uri")
+	appUID, err := uuid.Parse(clientID)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorClient, "invalid client_id")
+		return
+	}
+
+	// errors are not redirected until redirect uri is known to belong to the client
+	registered, err := h.db.isRegisteredRedirectURI(appUID, redirectURI)
+	if err == errNotFound {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorClient, "unknown client")
+		return
+	} else if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, oauthErrorServer, "")
+		return
+	}
+
+	if !registered {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "redirect_uri is not registered")
+		return
+	}
+
+	redirect := func(params url.Values) {
+		if state := r.FormValue("state"); state != "" {
+			params.Set("state", state)
+		}
+
+		u, _ := url.Parse(redirectURI)
+		query := u.Query()
+		for k, v := range params {
+			query[k] = v
+		}
+
+		u.RawQuery = query.Encode()
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	}
+
+	if r.FormValue("response_type") != responseTypeCode {
+		redirect(url.Values{"error": {"unsupported_response_type"}})
+		return
+	}
+
+	req := new(pb.GetOAuthCodeRequest)
+	req.AppUid = clientID
+	req.RedirectUri = redirectURI
+	req.Username = r.PostFormValue("username")
+	req.Password = r.PostFormValue("password")
+	req.CodeChallenge = r.FormValue("code_challenge")
+	req.CodeChallengeMethod = r.FormValue("code_challenge_method")
+	req.Scopes = strings.Fields(r.FormValue("scope"))
+	req.Nonce = r.FormValue("nonce")
+
+	var resp *pb.GetOAuthCodeResponse
+	err = h.invoke(w, r, "/user.user/GetOAuthCode", req, func(ctx context.Context) (err error) {
+		resp, err = h.GetOAuthCode(ctx, req)
+		return err
+	})
+	if err != nil {
+		errorCode := oauthErrorDenied
+		switch {
+		case err == statusInvalidScope:
+			errorCode = oauthErrorScope
+		case status.Code(err) == codes.InvalidArgument:
+			errorCode = oauthErrorRequest
+		case status.Code(err) == codes.ResourceExhausted:
+			errorCode = oauthErrorBusy
+		case status.Code(err) == codes.Internal:
+			errorCode = oauthErrorServer
+		}
+
+		redirect(url.Values{"error": {errorCode}, "error_description": {status.Convert(err).Message()}})
+		return
+	}
+
+	redirect(url.Values{"code": {resp.Code}})
+}
+
+// token handles access token requests described in RFC 6749 sections 4.1.3 and 6
+func (h *httpServer) token(w http.ResponseWriter, r *http.Request) {
+	if !parsePostForm(w, r) {
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case GrantAuthorizationCode:
+		h.tokenFromCode(w, r)
+	case grantTypeRefresh:
+		h.tokenFromRefreshToken(w, r)
+	case GrantClientCredentials:
+		h.tokenFromClientCredentials(w, r)
+	case "":
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "grant_type is required")
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+	}
+}
+
+func (h *httpServer) tokenFromCode(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret := clientCredentials(r)
+
+	req := new(pb.GetTokenFromCodeRequest)
+	req.AppUid = clientID
+	req.AppSecret = clientSecret
+	req.Code = r.PostFormValue("code")
+	req.RedirectUri = r.PostFormValue("redirect_uri")
+	req.CodeVerifier = r.PostFormValue("code_verifier")
+
+	var resp *pb.GetTokenFromCodeResponse
+	err := h.invoke(w, r, "/user.user/GetTokenFromCode", req, func(ctx context.Context) (err error) {
+		resp, err = h.GetTokenFromCode(ctx, req)
+		return err
+	})
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, &tokenResponse{
+			AccessToken:  resp.AccessToken,
+			TokenType:    tokenTypeBearer,
+			ExpiresIn:    resp.ExpiresIn,
+			RefreshToken: resp.RefreshToken,
+			IDToken:      resp.IdToken,
+		})
+	case err == statusInvalidUserToken, err == statusInvalidRedirect, err == statusInvalidVerifier:
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorGrant, status.Convert(err).Message())
+	case status.Code(err) == codes.ResourceExhausted:
+		writeOAuthError(w, http.StatusTooManyRequests, oauthErrorBusy, status.Convert(err).Message())
+	case status.Code(err) == codes.Internal:
+		writeOAuthError(w, http.StatusInternalServerError, oauthErrorServer, "")
+	default:
+		writeOAuthError(w, http.StatusUnauthorized, oauthErrorClient, "client authentication failed")
+	}
+}
+
+// tokenFromRefreshToken refreshes token, client must authenticate and can only refresh tokens issued to it
+// as required by RFC 6749 section 6
+func (h *httpServer) tokenFromRefreshToken(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret := clientCredentials(r)
+
+	req := new(pb.RefreshAccessTokenRequest)
+	req.RefreshToken = r.PostFormValue("refresh_token")
+
+	var resp *pb.RefreshAccessTokenResponse
+	err := h.invoke(w, r, "/user.user/RefreshAccessToken", req, func(ctx context.Context) (err error) {
+		resp, err = h.refreshClientToken(ctx, clientID, clientSecret, req)
+		return err
+	})
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, &tokenResponse{
+			AccessToken:  resp.AccessToken,
+			TokenType:    tokenTypeBearer,
+			ExpiresIn:    resp.ExpiresIn,
+			RefreshToken: resp.RefreshToken,
+		})
+	case err == statusInvalidUUID, err == statusNotFound, err == statusInvalidAppCredentials:
+		writeOAuthError(w, http.StatusUnauthorized, oauthErrorClient, "client authentication failed")
+	case status.Code(err) == codes.ResourceExhausted:
+		writeOAuthError(w, http.StatusTooManyRequests, oauthErrorBusy, status.Convert(err).Message())
+	case status.Code(err) == codes.Internal:
+		writeOAuthError(w, http.StatusInternalServerError, oauthErrorServer, "")
+	default:
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorGrant, status.Convert(err).Message())
+	}
+}
+
+// refreshClientToken refreshes token of app with credentials clientID and clientSecret
+func (h *httpServer) refreshClientToken(ctx context.Context, clientID, clientSecret string, req *pb.RefreshAccessTokenRequest) (*pb.RefreshAccessTokenResponse, error) {
+	appUID, _, err := h.authenticateApp(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := h.getRefreshToken(req.RefreshToken)
+	if err == errTokenNotFound {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	if info.ClientApp != appUID.String() {
+		return nil, statusInvalidUserToken
+	}
+
+	return h.RefreshAccessToken(ctx, req)
+}
+
+func (h *httpServer) tokenFromClientCredentials(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret := clientCredentials(r)
+
+	req := new(pb.GetClientCredentialsTokenRequest)
+	req.AppUid = clientID
+	req.AppSecret = clientSecret
+	req.Scopes = strings.Fields(r.PostFormValue("scope"))
+
+	var resp *pb.GetClientCredentialsTokenResponse
+	err := h.invoke(w, r, "/user.user/GetClientCredentialsToken", req, func(ctx context.Context) (err error) {
+		resp, err = h.GetClientCredentialsToken(ctx, req)
+		return err
+	})
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, &tokenResponse{
+			AccessToken: resp.AccessToken,
+			TokenType:   tokenTypeBearer,
+			ExpiresIn:   resp.ExpiresIn,
+		})
+	case err == statusInvalidScope:
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorScope, status.Convert(err).Message())
+	case err == statusUnauthorizedClient:
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorUnauthorizedClient, status.Convert(err).Message())
+	case status.Code(err) == codes.ResourceExhausted:
+		writeOAuthError(w, http.StatusTooManyRequests, oauthErrorBusy, status.Convert(err).Message())
+	case status.Code(err) == codes.Internal:
+		writeOAuthError(w, http.StatusInternalServerError, oauthErrorServer, "")
+	default:
+		writeOAuthError(w, http.StatusUnauthorized, oauthErrorClient, "client authentication failed")
+	}
+}
+
+// revoke handles token revocation request described in RFC 7009, client must authenticate and tokens issued
+// to other clients are left intact
+func (h *httpServer) revoke(w http.ResponseWriter, r *http.Request) {
+	if !parsePostForm(w, r) {
+		return
+	}
+
+	token := r.PostFormValue("token")
+	if token == "" {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "token is required")
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+
+	req := new(pb.RevokeTokenRequest)
+	req.Token = token
+	req.TokenTypeHint = r.PostFormValue("token_type_hint")
+
+	err := h.invoke(w, r, "/user.user/RevokeToken", req, func(ctx context.Context) error {
+		return h.revokeClientToken(ctx, clientID, clientSecret, req)
+	})
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case err == statusInvalidUUID, err == statusNotFound, err == statusInvalidAppCredentials:
+		writeOAuthError(w, http.StatusUnauthorized, oauthErrorClient, "client authentication failed")
+	case status.Code(err) == codes.ResourceExhausted:
+		writeOAuthError(w, http.StatusTooManyRequests, oauthErrorBusy, status.Convert(err).Message())
+	default:
+		writeOAuthError(w, http.StatusServiceUnavailable, oauthErrorServer, "")
+	}
+}
+
+// revokeClientToken revokes token if it was issued to app with credentials clientID and clientSecret
+func (h *httpServer) revokeClientToken(ctx context.Context, clientID, clientSecret string, req *pb.RevokeTokenRequest) error {
+	appUID, _, err := h.authenticateApp(clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	owner, err := h.IntrospectToken(ctx, &pb.IntrospectTokenRequest{Token: req.Token, TokenTypeHint: req.TokenTypeHint})
+	if err != nil {
+		return err
+	}
+
+	// response is the same as for revoked token, so client can not learn whether token of another client exists
+	if !owner.Active || owner.ClientApp != appUID.String() {
+		return nil
+	}
+
+	_, err = h.RevokeToken(ctx, req)
+	return err
+}
+
+// introspect handles token introspection request described in RFC 7662, caller must authenticate as confidential client
+func (h *httpServer) introspect(w http.ResponseWriter, r *http.Request) {
+	if !parsePostForm(w, r) {
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+
+	token := r.PostFormValue("token")
+	if token == "" {
+		writeOAuthError(w, http.StatusBadRequest, oauthErrorRequest, "token is required")
+		return
+	}
+
+	req := new(pb.IntrospectTokenRequest)
+	req.Token = token
+	req.TokenTypeHint = r.PostFormValue("token_type_hint")
+
+	var resp *pb.IntrospectTokenResponse
+	err := h.invoke(w, r, "/user.user/IntrospectToken", req, func(ctx context.Context) (err error) {
+		resp, err = h.introspectClientToken(ctx, clientID, clientSecret, req)
+		return err
+	})
+	switch {
+	case err == statusInvalidUUID, err == statusNotFound, err == statusInvalidAppCredentials:
+		writeOAuthError(w, http.StatusUnauthorized, oauthErrorClient, "client authentication failed")
+		return
+	case status.Code(err) == codes.ResourceExhausted:
+		writeOAuthError(w, http.StatusTooManyRequests, oauthErrorBusy, status.Convert(err).Message())
+		return
+	case err != nil:
+		writeOAuthError(w, http.StatusInternalServerError, oauthErrorServer, "")
+		return
+	}
+
+	if !resp.Active {
+		writeJSON(w, http.StatusOK, &introspectionResponse{Active: false})
+		return
+	}
+
+	result := &introspectionResponse{
+		Active:      true,
+		Scope:       strings.Join(resp.Scopes, " "),
+		ClientID:    resp.ClientApp,
+		Subject:     resp.Subject,
+		SubjectType: resp.SubjectType,
+		IssuedAt:    resp.IssuedAt,
+		ExpiresAt:   resp.ExpiresAt,
+		Issuer:      h.baseURL(r),
+	}
+	if resp.TokenType == accessTokenTypeHint {
+		result.TokenType = tokenTypeBearer
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// introspectClientToken returns token metadata if app with credentials clientID and clientSecret is confidential
+func (h *httpServer) introspectClientToken(ctx context.Context, clientID, clientSecret string, req *pb.IntrospectTokenRequest) (*pb.IntrospectTokenResponse, error) {
+	_, app, err := h.authenticateApp(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// public app is identified by uid alone, so anyone could use it to probe tokens
+	if app.IsPublic {
+		return nil, statusInvalidAppCredentials
+	}
+
+	return h.IntrospectToken(ctx, req)
+}
+
+// bearerToken returns access token sent as described in RFC 6750 section 2
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	if header := r.Header.Get("Authorization"); len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return header[len(prefix):]
+	}
+
+	return r.PostFormValue("access_token")
+}
+
+// userInfo handles user info request described in OpenID Connect Core 1.0 section 5.3
+func (h *httpServer) userInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := new(pb.GetUserClaimsRequest)
+	req.UserToken = bearerToken(r)
+
+	var resp *pb.GetUserClaimsResponse
+	err := h.invoke(w, r, "/user.user/GetUserClaims", req, func(ctx context.Context) (err error) {
+		resp, err = h.GetUserClaims(ctx, req)
+		return err
+	})
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, &userInfoResponse{resp.Sub, resp.PreferredUsername})
+	case err == statusInsufficientScope:
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		w.WriteHeader(http.StatusForbidden)
+	case status.Code(err) == codes.ResourceExhausted:
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	case status.Code(err) == codes.Internal:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	default:
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+}
+
+// jwks serves keys used to sign access tokens
+func (h *httpServer) jwks(w http.ResponseWriter, r *http.Request) {
+	req := new(pb.GetPublicKeysRequest)
+	var resp *pb.GetPublicKeysResponse
+	err := h.invoke(w, r, "/user.user/GetPublicKeys", req, func(ctx context.Context) (err error) {
+		resp, err = h.GetPublicKeys(ctx, req)
+		return err
+	})
+	if err == statusSigningDisabled {
+		http.NotFound(w, r)
+		return
+	} else if status.Code(err) == codes.ResourceExhausted {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	} else if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Write([]byte(resp.Jwks))
+}
+
+// openIDConfiguration serves discovery document
+func (h *httpServer) openIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	base := h.baseURL(r)
+	metadata := &providerMetadata{
+		Issuer:                            base,
+		AuthorizationEndpoint:             base + authorizePath,
+		TokenEndpoint:                     base + tokenPath,
+		RevocationEndpoint:                base + revokePath,
+		IntrospectionEndpoint:             base + introspectPath,
+		UserInfoEndpoint:                  base + userInfoPath,
+		ScopesSupported:                   []string{ScopeOpenID, ScopeProfile},
+		ResponseTypesSupported:            []string{responseTypeCode},
+		GrantTypesSupported:               []string{GrantAuthorizationCode, grantTypeRefresh, GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "preferred_username"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengePlain, codeChallengeS256},
+	}
+
+	if h.signer != nil {
+		metadata.JWKSURI = base + jwksPath
+		metadata.IDTokenSigningAlgValuesSupported = []string{h.signer.algorithm()}
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	json.NewEncoder(w).Encode(metadata)
+}
@@ -0,0 +1,136 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// postForm sends form to handler as client with address 10.0.0.1
+func postForm(h http.Handler, path string, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", formContentType)
+	r.RemoteAddr = "10.0.0.1:50000"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthorizeRequiresPost(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	s.loginLimits = LoginLimits{UsernameAttempts: 1, AddressAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	addUser(t, s, db, "user", "password", false)
+	app := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	form := url.Values{
+		"client_id":     {app.UID.String()},
+		"redirect_uri":  {testRedirectURI},
+		"response_type": {responseTypeCode},
+		"username":      {"user"},
+	}
+	h := s.HTTPHandler("")
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, authorizePath+"?"+form.Encode(), nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("GET: status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+		}
+
+		if allow := w.Header().Get("Allow"); allow != http.MethodPost {
+			t.Errorf("Allow = %q, want %q", allow, http.MethodPost)
+		}
+	}
+
+	// rejected requests are not counted as failed logins
+	form.Set("password", "password")
+	w := postForm(h, authorizePath, form)
+	location, err := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || err != nil || location.Query().Get("code") == "" {
+		t.Errorf("POST: status = %d, location = %q, want redirect with code", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestIntrospectClientAuthentication(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "user", "password", false)
+	token := issueUserToken(t, s, uid, ScopeProfile)
+	confidential := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	public := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+
+	tests := []struct {
+		name   string
+		app    string
+		secret string
+		want   int
+	}{
+		{"confidential app", confidential.UID.String(), confidential.Secret.String(), http.StatusOK},
+		{"wrong secret", confidential.UID.String(), uuid.New().String(), http.StatusUnauthorized},
+		{"invalid secret", confidential.UID.String(), "secret", http.StatusUnauthorized},
+		{"unknown app", uuid.New().String(), uuid.New().String(), http.StatusUnauthorized},
+		{"public app", public.UID.String(), "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"token": {token}, "client_id": {tt.app}, "client_secret": {tt.secret}}
+			if w := postForm(s.HTTPHandler(""), introspectPath, form); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntrospectClientAuthenticationIsRateLimited(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	app := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	s.rateLimits = map[string]RateLimit{
+		"/user.user/IntrospectToken": {Requests: 1, Period: time.Hour, Key: RateLimitByPeer},
+	}
+
+	form := url.Values{"token": {uuid.New().String()}, "client_id": {app.UID.String()}, "client_secret": {uuid.New().String()}}
+	h := s.HTTPHandler("")
+	if w := postForm(h, introspectPath, form); w.Code != http.StatusUnauthorized {
+		t.Fatalf("first guess: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	if w := postForm(h, introspectPath, form); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second guess: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitedResponseHasRetryAfter(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	app := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	s.rateLimits = map[string]RateLimit{
+		"/user.user/GetClientCredentialsToken": {Requests: 1, Period: time.Hour, Key: RateLimitByApp},
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {app.UID.String()}, "client_secret": {app.Secret.String()}}
+	h := s.HTTPHandler("")
+	w := postForm(h, tokenPath, form)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "" {
+		t.Errorf("first request: Retry-After = %q, want none", retryAfter)
+	}
+
+	w = postForm(h, tokenPath, form)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "3600" {
+		t.Errorf("second request: Retry-After = %q, want %q", retryAfter, "3600")
+	}
+}
@@ -0,0 +1,210 @@
+package user
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+var (
+	errInvalidSigningMethod = errors.New("invalid token signing method")
+	errInvalidIssuer        = errors.New("invalid token issuer")
+)
+
+// JWTSigner issues and verifies self-contained access tokens
+type JWTSigner struct {
+	keys   *KeyManager
+	issuer string
+}
+
+// AccessTokenClaims describes claims of self-contained access token
+type AccessTokenClaims struct {
+	IsAdmin     bool   `json:"is_admin"`
+	SubjectType string `json:"subject_type"`
+	// Scope contains space separated list of granted scopes
+	Scope string `json:"scope,omitempty"`
+	jwt.StandardClaims
+}
+
+// IDTokenClaims describes claims of OpenID Connect ID token
+type IDTokenClaims struct {
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time"`
+	jwt.StandardClaims
+}
+
+// NewJWTSigner returns signer using keys from key manager
+func NewJWTSigner(keys *KeyManager, issuer string) *JWTSigner {
+	return &JWTSigner{keys, issuer}
+}
+
+func (s *JWTSigner) sign(id string, info *tokenInfo, isAdmin bool, expiration time.Duration) (string, error) {
+	now := time.Now()
+	claims := AccessTokenClaims{
+		IsAdmin:     isAdmin,
+		SubjectType: info.SubjectType,
+		Scope:       strings.Join(info.Scopes, " "),
+		StandardClaims: jwt.StandardClaims{
+			Id:        id,
+			Subject:   info.UID,
+			Issuer:    s.issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(expiration).Unix(),
+		},
+	}
+
+	return s.signClaims(claims)
+}
+
+// signIDToken issues ID token for app, claims contain only user info allowed by scopes.
+// ID token has no id, so it can not be used as access token
+func (s *JWTSigner) signIDToken(appUID string, user *User, scopes []string, nonce string, authTime time.Time, expiration time.Duration) (string, error) {
+	now := time.Now()
+	claims := IDTokenClaims{
+		Nonce:    nonce,
+		AuthTime: authTime.Unix(),
+		StandardClaims: jwt.StandardClaims{
+			Subject:   user.UID.String(),
+			Audience:  appUID,
+			Issuer:    s.issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(expiration).Unix(),
+		},
+	}
+
+	if hasScope(scopes, ScopeProfile) {
+		claims.PreferredUsername = user.Username
+	}
+
+	return s.signClaims(claims)
+}
+
+// limitLifetime shortens lifetime of signed token to retention of signing keys, otherwise the key could be
+// removed while token is still valid
+func (s *JWTSigner) limitLifetime(lifetime time.Duration) time.Duration {
+	if lifetime > s.keys.retention {
+		return s.keys.retention
+	}
+
+	return lifetime
+}
+
+// Close stops reloading keys
+func (s *JWTSigner) Close() error {
+	return s.keys.Close()
+}
+
+// algorithm returns name of algorithm used to sign new tokens
+func (s *JWTSigner) algorithm() string {
+	return s.keys.activeKey().method.Alg()
+}
+
+func (s *JWTSigner) signClaims(claims jwt.Claims) (string, error) {
+	key := s.keys.activeKey()
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.key)
+}
+
+func (s *JWTSigner) verify(token string) (*AccessTokenClaims, error) {
+	claims := new(AccessTokenClaims)
+	_, err := jwt.ParseWithClaims(token, claims, s.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != s.issuer {
+		return nil, errInvalidIssuer
+	}
+
+	return claims, nil
+}
+
+func (s *JWTSigner) verificationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	key, err := s.keys.verificationKey(id)
+	if err == errUnknownKey && id != "" {
+		// key may have been rotated by another instance
+		if err := s.keys.reloadForUnknownKey(); err != nil {
+			return nil, err
+		}
+
+		key, err = s.keys.verificationKey(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errInvalidSigningMethod
+	}
+
+	return key.key.Public(), nil
+}
+
+// jsonWebKey describes public key in JWK format (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func encodeBigInt(n *big.Int, size int) string {
+	b := n.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (k *signingKey) publicKey() jsonWebKey {
+	result := jsonWebKey{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+	switch key := k.key.Public().(type) {
+	case *rsa.PublicKey:
+		result.Kty = "RSA"
+		result.N = encodeBigInt(key.N, 0)
+		result.E = encodeBigInt(big.NewInt(int64(key.E)), 0)
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		result.Kty = "EC"
+		result.Crv = key.Curve.Params().Name
+		result.X = encodeBigInt(key.X, size)
+		result.Y = encodeBigInt(key.Y, size)
+	}
+
+	return result
+}
+
+// JWKS returns JSON Web Key Set with all keys which may have signed not yet expired tokens
+func (s *JWTSigner) JWKS() ([]byte, error) {
+	keys := s.keys.validKeys()
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{make([]jsonWebKey, 0, len(keys))}
+
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, key.publicKey())
+	}
+
+	return json.Marshal(jwks)
+}
+
+// Rotate makes a new key active, tokens signed by previous keys remain valid
+func (s *JWTSigner) Rotate() (string, error) {
+	return s.keys.Rotate()
+}
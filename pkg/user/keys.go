@@ -0,0 +1,356 @@
+package user
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+const (
+	keyFileExt       = ".pem"
+	keyCreatedHeader = "Created"
+	rsaKeyBits       = 2048
+
+	// keyReloadInterval is how often keys rotated by other instances are picked up for signing
+	keyReloadInterval = time.Minute
+	// minKeyReloadInterval limits reloads caused by tokens signed with unknown keys
+	minKeyReloadInterval = 10 * time.Second
+)
+
+var (
+	errUnsupportedKey = errors.New("unsupported signing key, expected RSA or EC P-256 private key")
+	errUnknownKey     = errors.New("unknown signing key")
+)
+
+// signingKey describes private key used to sign tokens
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	key     crypto.Signer
+	created time.Time
+}
+
+// KeyManager keeps signing keys in a directory, the newest key is used for signing
+// and older keys are kept for verification until tokens signed by them expire
+type KeyManager struct {
+	mu        sync.RWMutex
+	dir       string
+	retention time.Duration
+	// keys are sorted by creation time, the last one is active
+	keys       []*signingKey
+	reloadedAt time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewKeyManager loads keys from dir, generating a new EC P-256 key if there are none, and reloads them every
+// minute until closed. Retired keys are kept for retention, lifetime of signed tokens is limited by it
+func NewKeyManager(dir string, retention time.Duration) (*KeyManager, error) {
+	m := &KeyManager{dir: dir, retention: retention, done: make(chan struct{})}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+
+	if len(m.keys) == 0 {
+		if _, err := m.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	go m.reloadPeriodically()
+	return m, nil
+}
+
+// Close stops reloading keys
+func (m *KeyManager) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+
+	return nil
+}
+
+func (m *KeyManager) reloadPeriodically() {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			if err := m.Reload(); err != nil {
+				log.Printf("failed to reload signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// reloadForUnknownKey reloads keys when token is signed by unknown key, at most once per minKeyReloadInterval,
+// so tokens with made up key ids can not make every request read the directory
+func (m *KeyManager) reloadForUnknownKey() error {
+	m.mu.Lock()
+	if time.Since(m.reloadedAt) < minKeyReloadInterval {
+		m.mu.Unlock()
+		return errUnknownKey
+	}
+
+	m.reloadedAt = time.Now()
+	m.mu.Unlock()
+
+	return m.Reload()
+}
+
+// Reload reads keys from disk, which picks up keys rotated by other instances sharing the directory.
+// Loaded keys are kept if the directory has no keys
+func (m *KeyManager) Reload() error {
+	files, err := filepath.Glob(filepath.Join(m.dir, "*"+keyFileExt))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*signingKey, 0, len(files))
+	for _, file := range files {
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reloadedAt = time.Now()
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].created.Before(keys[j].created)
+	})
+
+	m.keys = keys
+
+	return nil
+}
+
+// Rotate generates a new active key of the same type as the current one and removes expired keys
+func (m *KeyManager) Rotate() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		key crypto.Signer
+		err error
+	)
+	if len(m.keys) > 0 && m.keys[len(m.keys)-1].method == jwt.SigningMethodRS256 {
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	newKey, err := newSigningKey(uuid.New().String(), key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if err := m.save(newKey); err != nil {
+		return "", err
+	}
+
+	m.keys = append(m.keys, newKey)
+	return newKey.id, m.prune()
+}
+
+// activeKey returns key which should be used for signing
+func (m *KeyManager) activeKey() *signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.keys[len(m.keys)-1]
+}
+
+// verificationKey returns key with given id if tokens signed by it may still be valid
+func (m *KeyManager) verificationKey(id string) (*signingKey, error) {
+	for _, key := range m.validKeys() {
+		if key.id == id {
+			return key, nil
+		}
+	}
+
+	return nil, errUnknownKey
+}
+
+// validKeys returns keys which may have signed not yet expired tokens
+func (m *KeyManager) validKeys() []*signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	result := make([]*signingKey, 0, len(m.keys))
+	for i, key := range m.keys {
+		if i == len(m.keys)-1 || now.Before(m.keys[i+1].created.Add(m.retention)) {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+// prune removes keys retired more than retention ago, must be called with mu held
+func (m *KeyManager) prune() error {
+	now := time.Now()
+	for len(m.keys) > 1 && !now.Before(m.keys[1].created.Add(m.retention)) {
+		err := os.Remove(m.keyFile(m.keys[0].id))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		m.keys = m.keys[1:]
+	}
+
+	return nil
+}
+
+func (m *KeyManager) keyFile(id string) string {
+	return filepath.Join(m.dir, id+keyFileExt)
+}
+
+// save atomically writes key to disk
+func (m *KeyManager) save(key *signingKey) error {
+	block := &pem.Block{
+		Headers: map[string]string{keyCreatedHeader: key.created.UTC().Format(time.RFC3339Nano)},
+	}
+
+	var err error
+	switch k := key.key.(type) {
+	case *rsa.PrivateKey:
+		block.Type = "RSA PRIVATE KEY"
+		block.Bytes = x509.MarshalPKCS1PrivateKey(k)
+	case *ecdsa.PrivateKey:
+		block.Type = "EC PRIVATE KEY"
+		block.Bytes, err = x509.MarshalECPrivateKey(k)
+	default:
+		err = errUnsupportedKey
+	}
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(m.dir, ".key")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, block); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), m.keyFile(key.id))
+}
+
+// loadSigningKey reads PEM encoded private key, file name is used as key id.
+// Creation time is taken from the Created header or file modification time if it is absent
+func loadSigningKey(file string) (*signingKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errUnsupportedKey
+	}
+
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var created time.Time
+	if value, ok := block.Headers[keyCreatedHeader]; ok {
+		created, err = time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		created = info.ModTime()
+	}
+
+	id := strings.TrimSuffix(filepath.Base(file), keyFileExt)
+	return newSigningKey(id, key, created)
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errUnsupportedKey
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errUnsupportedKey
+	}
+
+	return signer, nil
+}
+
+func newSigningKey(id string, key crypto.Signer, created time.Time) (*signingKey, error) {
+	result := &signingKey{id: id, key: key, created: created}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		result.method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errUnsupportedKey
+		}
+
+		result.method = jwt.SigningMethodES256
+	default:
+		return nil, errUnsupportedKey
+	}
+
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// GrantPassword is a grant of tokens obtained with username and password
+	GrantPassword = "password"
+	// GrantAuthorizationCode is a grant of tokens obtained with oauth code
+	GrantAuthorizationCode = "authorization_code"
+	// GrantClientCredentials is a grant of tokens obtained by app for itself
+	GrantClientCredentials = "client_credentials"
+)
+
+var (
+	errSessionExpired = errors.New("session expired")
+)
+
+// TokenLifetimes describes how long issued tokens are valid, zero fields are inherited from less specific settings
+type TokenLifetimes struct {
+	AccessToken  time.Duration
+	RefreshToken time.Duration
+	OAuthCode    time.Duration
+	IDToken      time.Duration
+	// Session limits time since user logged in after which neither sliding expiration nor refresh token rotation
+	// can extend tokens
+	Session time.Duration
+}
+
+// override returns lifetimes with non-zero fields of o replacing fields of l
+func (l TokenLifetimes) override(o TokenLifetimes) TokenLifetimes {
+	if o.AccessToken > 0 {
+		l.AccessToken = o.AccessToken
+	}
+
+	if o.RefreshToken > 0 {
+		l.RefreshToken = o.RefreshToken
+	}
+
+	if o.OAuthCode > 0 {
+		l.OAuthCode = o.OAuthCode
+	}
+
+	if o.IDToken > 0 {
+		l.IDToken = o.IDToken
+	}
+
+	if o.Session > 0 {
+		l.Session = o.Session
+	}
+
+	return l
+}
+
+// maxAppLifetime returns maximal lifetime in seconds app can set for tokens whose lifetime on server is limit,
+// it fits into int32 database columns and does not overflow when converted to time.Duration
+func maxAppLifetime(limit time.Duration) int64 {
+	max := int64(math.MaxInt32)
+	if seconds := int64(limit / time.Second); limit > 0 && seconds < max {
+		max = seconds
+	}
+
+	return max
+}
+
+// sessionExpiresAt returns unix time when session started now ends or zero if session is not limited
+func (l TokenLifetimes) sessionExpiresAt() int64 {
+	if l.Session <= 0 {
+		return 0
+	}
+
+	return time.Now().Add(l.Session).Unix()
+}
+
+// expiration returns lifetime limited by the end of token session or errSessionExpired
+func (info *tokenInfo) expiration(lifetime time.Duration) (time.Duration, error) {
+	if info.SessionExpiresAt == 0 {
+		return lifetime, nil
+	}
+
+	left := time.Until(time.Unix(info.SessionExpiresAt, 0))
+	if left <= 0 {
+		return 0, errSessionExpired
+	}
+
+	if left < lifetime {
+		return left, nil
+	}
+
+	return lifetime, nil
+}
+
+// lifetimesFor returns lifetimes of tokens issued with grant to app, app settings override grant settings
+// which override server defaults. App is nil for tokens issued without app
+func (s *Server) lifetimesFor(grant string, app *AppInfo) TokenLifetimes {
+	result := s.lifetimes.override(s.grantLifetimes[grant])
+	if app != nil {
+		result = result.override(app.Lifetimes)
+	}
+
+	return result
+}
+
+// appLifetimesFor is like lifetimesFor, but looks up app by uid, empty uid means no app
+func (s *Server) appLifetimesFor(grant, appUID string) (TokenLifetimes, error) {
+	if appUID == "" {
+		return s.lifetimesFor(grant, nil), nil
+	}
+
+	uid, err := uuid.Parse(appUID)
+	if err != nil {
+		return TokenLifetimes{}, err
+	}
+
+	app, err := s.db.getAppInfo(uid)
+	if err != nil {
+		return TokenLifetimes{}, err
+	}
+
+	return s.lifetimesFor(grant, app), nil
+}
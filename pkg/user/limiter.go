@@ -0,0 +1,182 @@
+package user
+
+import (
+	"context"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	retryAfterKey    = "retry-after"
+	forwardedForKey  = "x-forwarded-for"
+	maxBackoffDouble = 30
+)
+
+var (
+	statusTooManyLogins = status.Error(codes.ResourceExhausted, "too many failed login attempts, retry later")
+)
+
+// LoginLimits describes throttling of failed logins per username and per client address
+type LoginLimits struct {
+	// UsernameAttempts and AddressAttempts are numbers of failed logins allowed before backoff starts
+	UsernameAttempts int
+	AddressAttempts  int
+	// BaseDelay is a delay after the first failure over the limit, it doubles with every next failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+	// TrustForwardedFor takes client address from x-forwarded-for metadata, it must be enabled only
+	// when service is reachable through proxy which sets it, otherwise clients can choose their address
+	TrustForwardedFor bool
+}
+
+// DefaultLoginLimits are limits used unless other limits are configured
+var DefaultLoginLimits = LoginLimits{
+	UsernameAttempts: 5,
+	AddressAttempts:  20,
+	BaseDelay:        time.Second,
+	MaxDelay:         15 * time.Minute,
+	Window:           time.Hour,
+}
+
+// delay returns how long logins are blocked after failures-th failure
+func (l LoginLimits) delay(failures int64, allowed int) time.Duration {
+	over := failures - int64(allowed)
+	if over <= 0 {
+		return 0
+	}
+
+	if over > maxBackoffDouble {
+		return l.MaxDelay
+	}
+
+	delay := l.BaseDelay << uint(over-1)
+	if delay <= 0 || delay > l.MaxDelay {
+		return l.MaxDelay
+	}
+
+	return delay
+}
+
+// loginCounter counts failed logins of a username or from an address
+type loginCounter struct {
+	key     string
+	allowed int
+}
+
+func (c loginCounter) blockedKey() string {
+	return c.key + ":blocked"
+}
+
+// loginAttempt tracks a single attempt to log in with password
+type loginAttempt struct {
+	s *Server
+	// counters of username and, if it is known, of client address
+	counters []loginCounter
+}
+
+// setRetryAfter tells client how long to wait before the next request in retry-after trailer, rounded up to seconds
+func setRetryAfter(ctx context.Context, wait time.Duration) {
+	seconds := int64((wait + time.Second - 1) / time.Second)
+	grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, strconv.FormatInt(seconds, 10)))
+}
+
+// clientAddress returns address of client which made the request or empty string if it is unknown
+func clientAddress(ctx context.Context, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(forwardedForKey); len(values) > 0 {
+				// the first address is the original client, the rest are proxies
+				if addr := strings.TrimSpace(strings.Split(values[0], ",")[0]); addr != "" {
+					return addr
+				}
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// startLogin returns statusTooManyLogins with retry-after trailer if logins of username or from client address
+// are blocked, otherwise it returns attempt which must be reported as failed or succeeded
+func (s *Server) startLogin(ctx context.Context, username string) (*loginAttempt, error) {
+	if normalized, err := normalizeUsername(username); err == nil {
+		username = normalized
+	}
+
+	attempt := &loginAttempt{s: s}
+	attempt.counters = append(attempt.counters, loginCounter{"user:" + username, s.loginLimits.UsernameAttempts})
+	if addr := clientAddress(ctx, s.loginLimits.TrustForwardedFor); addr != "" {
+		attempt.counters = append(attempt.counters, loginCounter{"addr:" + addr, s.loginLimits.AddressAttempts})
+	}
+
+	var retryAfter time.Duration
+	for _, c := range attempt.counters {
+		ttl, err := s.loginAttemptStorage.TTL(c.blockedKey())
+		if err == errTokenNotFound {
+			continue
+		} else if err != nil {
+			return nil, internalError(err)
+		}
+
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	if retryAfter > 0 {
+		logins.WithLabelValues(loginThrottled).Inc()
+		setRetryAfter(ctx, retryAfter)
+		return nil, statusTooManyLogins
+	}
+
+	return attempt, nil
+}
+
+// failed counts failure and blocks further logins if there were too many of them
+func (a *loginAttempt) failed() {
+	for _, c := range a.counters {
+		failures, err := a.s.loginAttemptStorage.Increment(c.key, a.s.loginLimits.Window)
+		if err != nil {
+			log.Printf("failed to count failed login %s: %v", c.key, err)
+			continue
+		}
+
+		if delay := a.s.loginLimits.delay(failures, c.allowed); delay > 0 {
+			if err := a.s.loginAttemptStorage.Set(c.blockedKey(), strconv.FormatInt(failures, 10), delay); err != nil {
+				log.Printf("failed to block login %s: %v", c.key, err)
+			}
+		}
+	}
+}
+
+// succeeded resets failures of username. Failures from address are kept, otherwise an attacker could reset them
+// by logging into own account between guesses
+func (a *loginAttempt) succeeded() {
+	username := a.counters[0]
+	for _, key := range []string{username.key, username.blockedKey()} {
+		if err := a.s.loginAttemptStorage.Delete(key); err != nil {
+			log.Printf("failed to reset failed logins %s: %v", key, err)
+		}
+	}
+}
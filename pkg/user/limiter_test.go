@@ -0,0 +1,120 @@
+package user
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestLoginLimitsDelay(t *testing.T) {
+	limits := LoginLimits{BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	tests := []struct {
+		failures int64
+		allowed  int
+		want     time.Duration
+	}{
+		{0, 3, 0},
+		{3, 3, 0},
+		{4, 3, time.Second},
+		{5, 3, 2 * time.Second},
+		{9, 3, 32 * time.Second},
+		{10, 3, time.Minute},
+		{1000, 3, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := limits.delay(tt.failures, tt.allowed); got != tt.want {
+			t.Errorf("delay(%d, %d) = %v, want %v", tt.failures, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func clientAt(addr string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 50000}})
+}
+
+func TestLoginThrottle(t *testing.T) {
+	type login struct {
+		addr     string
+		username string
+		password string
+		want     error
+	}
+
+	tests := []struct {
+		name   string
+		logins []login
+	}{
+		{"username is blocked after allowed failures", []login{
+			{"10.0.0.1", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.2", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.3", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.4", "alice", "password", statusTooManyLogins},
+			{"10.0.0.4", "bob", "password", nil},
+		}},
+		{"username is blocked in any case", []login{
+			{"10.0.0.1", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "Alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "ALICE", "wrong", statusInvalidCredentials},
+			{"10.0.0.2", "alice", "password", statusTooManyLogins},
+		}},
+		{"unknown usernames are blocked too", []login{
+			{"10.0.0.1", "mallory", "wrong", statusInvalidCredentials},
+			{"10.0.0.2", "mallory", "wrong", statusInvalidCredentials},
+			{"10.0.0.3", "mallory", "wrong", statusInvalidCredentials},
+			{"10.0.0.4", "mallory", "wrong", statusTooManyLogins},
+		}},
+		{"success resets username failures", []login{
+			{"10.0.0.1", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "alice", "password", nil},
+			{"10.0.0.2", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.2", "alice", "wrong", statusInvalidCredentials},
+			{"10.0.0.2", "alice", "password", nil},
+		}},
+		{"address is blocked across usernames", []login{
+			{"10.0.0.1", "user1", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user2", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user3", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user4", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user5", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "bob", "password", statusTooManyLogins},
+			{"10.0.0.2", "bob", "password", nil},
+		}},
+		{"success does not reset address failures", []login{
+			{"10.0.0.1", "user1", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user2", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user3", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "user4", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "bob", "password", nil},
+			{"10.0.0.1", "user5", "wrong", statusInvalidCredentials},
+			{"10.0.0.1", "bob", "password", statusTooManyLogins},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			s := newTestServer(t, db)
+			s.loginLimits = LoginLimits{
+				UsernameAttempts: 2,
+				AddressAttempts:  4,
+				BaseDelay:        time.Minute,
+				MaxDelay:         time.Hour,
+				Window:           time.Hour,
+			}
+			addUser(t, s, db, "alice", "password", false)
+			addUser(t, s, db, "bob", "password", false)
+
+			for i, l := range tt.logins {
+				if _, err := s.authenticate(clientAt(l.addr), l.username, l.password); err != l.want {
+					t.Fatalf("login %d of %s from %s: got %v, want %v", i+1, l.username, l.addr, err, l.want)
+				}
+			}
+		})
+	}
+}
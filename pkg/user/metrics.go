@@ -0,0 +1,149 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	metricsNamespace = "user"
+	metricsPath      = "/metrics"
+)
+
+// login results
+const (
+	loginSuccess       = "success"
+	loginUnknownUser   = "unknown_user"
+	loginWrongPassword = "wrong_password"
+	loginThrottled     = "throttled"
+)
+
+// kinds of revocation, a single revocation may delete many tokens
+const (
+	revokeToken  = "token"
+	revokeFamily = "family"
+	revokeUser   = "user"
+)
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "grpc_requests_total",
+		Help:      "Number of handled gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of gRPC requests by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "grpc_rate_limited_total",
+		Help:      "Number of gRPC requests rejected by rate limits by method.",
+	}, []string{"method"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "logins_total",
+		Help:      "Number of attempts to log in with username and password by result.",
+	}, []string{"result"})
+
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tokens_issued_total",
+		Help:      "Number of issued tokens by type.",
+	}, []string{"type"})
+
+	tokensRefreshed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tokens_refreshed_total",
+		Help:      "Number of refresh tokens exchanged for new tokens.",
+	})
+
+	tokensRevoked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "token_revocations_total",
+		Help:      "Number of revocations by kind: single token, refresh token family or all tokens of user.",
+	}, []string{"kind"})
+
+	passwordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "password_hash_duration_seconds",
+		Help:      "Time spent hashing passwords and comparing them with hashes.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+	}, []string{"operation"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of PostgreSQL queries by query.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"query"})
+
+	tokenStoreDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Latency of Redis token store operations by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 14),
+	}, []string{"operation"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		rpcRequests,
+		rpcDuration,
+		rateLimited,
+		logins,
+		tokensIssued,
+		tokensRefreshed,
+		tokensRevoked,
+		passwordHashDuration,
+		dbQueryDuration,
+		tokenStoreDuration,
+	)
+}
+
+// observeDuration records time elapsed since start, it is meant to be deferred
+func observeDuration(observer prometheus.Observer, start time.Time) {
+	observer.Observe(time.Since(start).Seconds())
+}
+
+// observeRPC records count, status code and latency of gRPC requests
+func observeRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	rpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	rpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+// StartMetrics starts plaintext HTTP server exposing Prometheus metrics at /metrics.
+// It returns nil after server is stopped
+func (s *Server) StartMetrics(port int) error {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	if !s.addHTTPServer(server) {
+		return errServerStopped
+	}
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
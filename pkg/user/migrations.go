@@ -0,0 +1,351 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+var (
+	errNothingToRollBack = errors.New("no applied migrations")
+)
+
+// migration is a numbered schema change, down reverts up
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+	// backfill fills new columns after up is run in the same transaction, it is used when data can not be
+	// converted by SQL alone
+	backfill func(*sql.Tx) error
+}
+
+// migrations are applied in order, released migrations must never be changed, add a new one instead.
+// The first migrations use IF NOT EXISTS so that databases created before migrations were introduced
+// can be brought under version control by running migrate up
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_users_and_apps",
+		up: `
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users (
+    uid UUID PRIMARY KEY,
+    username VARCHAR(30) NOT NULL UNIQUE,
+    password_hash CHAR(60) NOT NULL,
+    is_admin BOOLEAN DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS apps (
+    uid UUID PRIMARY KEY,
+    secret UUID NOT NULL,
+    owner UUID REFERENCES users (uid),
+    name VARCHAR(30) NOT NULL
+);`,
+		down: `
+DROP TABLE apps;
+DROP TABLE users;`,
+	},
+	{
+		version: 2,
+		name:    "add_app_redirect_uris",
+		up: `
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS redirect_uris TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT FALSE;`,
+		down: `
+ALTER TABLE apps DROP COLUMN is_public;
+ALTER TABLE apps DROP COLUMN redirect_uris;`,
+	},
+	{
+		version: 3,
+		name:    "add_app_scopes",
+		up: `
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';`,
+		down: `
+ALTER TABLE apps DROP COLUMN scopes;`,
+	},
+	{
+		version: 4,
+		name:    "add_app_token_lifetimes",
+		// token lifetimes in seconds, zero means lifetime configured for the service
+		up: `
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS access_token_lifetime INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS refresh_token_lifetime INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS session_lifetime INTEGER NOT NULL DEFAULT 0;`,
+		down: `
+ALTER TABLE apps DROP COLUMN session_lifetime;
+ALTER TABLE apps DROP COLUMN refresh_token_lifetime;
+ALTER TABLE apps DROP COLUMN access_token_lifetime;`,
+	},
+	{
+		version: 5,
+		name:    "widen_password_hash",
+		// bcrypt hashes are exactly 60 characters, argon2id hashes are longer and depend on parameters.
+		// Rolling back fails while argon2id hashes are stored
+		up: `
+ALTER TABLE users ALTER COLUMN password_hash TYPE VARCHAR(255);`,
+		down: `
+ALTER TABLE users ALTER COLUMN password_hash TYPE CHAR(60);`,
+	},
+	{
+		version: 6,
+		name:    "add_normalized_username",
+		// existing usernames which can not be normalized or collide with another one after normalization are
+		// left without normalized username, such users are looked up by exact name
+		up: `
+ALTER TABLE users ADD COLUMN username_normalized VARCHAR(30);
+ALTER TABLE users ADD CONSTRAINT users_username_normalized_key UNIQUE (username_normalized);`,
+		down: `
+ALTER TABLE users DROP COLUMN username_normalized;`,
+		backfill: backfillNormalizedUsernames,
+	},
+}
+
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+// Migration describes state of schema migration
+type Migration struct {
+	Version int
+	Name    string
+	// AppliedAt is zero if migration is not applied
+	AppliedAt time.Time
+}
+
+// Migrator applies schema migrations embedded in binary
+type Migrator struct {
+	db *sql.DB
+}
+
+// NewMigrator returns migrator of database
+func NewMigrator(connString string) (*Migrator, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db}, nil
+}
+
+// Close closes database connection
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Status returns all known migrations along with time they were applied
+func (m *Migrator) Status() ([]Migration, error) {
+	applied, err := appliedMigrations(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Migration, len(migrations))
+	for i, mig := range migrations {
+		result[i] = Migration{mig.version, mig.name, applied[mig.version]}
+	}
+
+	return result, nil
+}
+
+// Up applies all pending migrations and returns them, each migration is applied in its own transaction
+func (m *Migrator) Up() ([]Migration, error) {
+	if _, err := m.db.Exec(createMigrationsTable); err != nil {
+		return nil, err
+	}
+
+	var result []Migration
+	for _, mig := range migrations {
+		applied, err := m.apply(mig, true)
+		if err != nil {
+			return result, fmt.Errorf("migration %d %s: %v", mig.version, mig.name, err)
+		}
+
+		if applied {
+			result = append(result, Migration{mig.version, mig.name, time.Now()})
+		}
+	}
+
+	return result, nil
+}
+
+// Down reverts the last applied migration and returns it
+func (m *Migrator) Down() (*Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if status[i].AppliedAt.IsZero() {
+			continue
+		}
+
+		if _, err := m.apply(migrations[i], false); err != nil {
+			return nil, fmt.Errorf("migration %d %s: %v", migrations[i].version, migrations[i].name, err)
+		}
+
+		return &status[i], nil
+	}
+
+	return nil, errNothingToRollBack
+}
+
+// apply runs up or down part of migration unless it was already done, it returns whether migration was run
+func (m *Migrator) apply(mig migration, up bool) (bool, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	// concurrent migrators wait for each other instead of applying the same migration twice
+	if _, err := tx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE"); err != nil {
+		return false, err
+	}
+
+	var applied bool
+	row := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)", mig.version)
+	if err := row.Scan(&applied); err != nil {
+		return false, err
+	}
+
+	if applied == up {
+		return false, nil
+	}
+
+	if up {
+		_, err = tx.Exec(mig.up)
+		if err == nil && mig.backfill != nil {
+			err = mig.backfill(tx)
+		}
+
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.version, mig.name)
+		}
+	} else {
+		_, err = tx.Exec(mig.down)
+		if err == nil {
+			_, err = tx.Exec("DELETE FROM schema_migrations WHERE version=$1", mig.version)
+		}
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// backfillNormalizedUsernames sets normalized usernames of existing users with the same normalization as is used
+// for new ones. When several usernames have the same normalized form, the one already in normalized form wins
+func backfillNormalizedUsernames(tx *sql.Tx) error {
+	type user struct {
+		uid, normalized string
+		isNormal        bool
+	}
+
+	rows, err := tx.Query("SELECT uid, username FROM users")
+	if err != nil {
+		return err
+	}
+
+	var users []user
+	for rows.Next() {
+		var uid, username string
+		if err := rows.Scan(&uid, &username); err != nil {
+			rows.Close()
+			return err
+		}
+
+		normalized, err := normalizeUsername(username)
+		if err != nil || utf8.RuneCountInString(normalized) > MaxUsernameLength {
+			continue
+		}
+
+		users = append(users, user{uid, normalized, normalized == username})
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].isNormal && !users[j].isNormal
+	})
+
+	taken := make(map[string]bool)
+	for _, u := range users {
+		if taken[u.normalized] {
+			continue
+		}
+
+		taken[u.normalized] = true
+		if _, err := tx.Exec("UPDATE users SET username_normalized=$1 WHERE uid=$2", u.normalized, u.uid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appliedMigrations returns times when migrations were applied by version
+func appliedMigrations(db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		// 42P01 is a code for undefined table, which means that no migrations were applied
+		if e, ok := err.(*pq.Error); ok && e.Code == "42P01" {
+			return map[int]time.Time{}, nil
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+// checkSchema returns error if some migrations are not applied to database
+func checkSchema(db *sql.DB) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	var pending []int
+	for _, mig := range migrations {
+		if _, ok := applied[mig.version]; !ok {
+			pending = append(pending, mig.version)
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind, migrations %v are not applied, run migrate up", pending)
+	}
+
+	return nil
+}
@@ -3,11 +3,11 @@ package user
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -16,10 +16,6 @@ var (
 	errUserExists = errors.New("user with this username already exists")
 )
 
-const (
-	bcryptCost = 4
-)
-
 // User describes public user info
 type User struct {
 	UID      uuid.UUID
@@ -29,16 +25,25 @@ type User struct {
 
 // App describes third-party app
 type App struct {
-	UID    uuid.UUID
-	Secret uuid.UUID
-	Owner  uuid.UUID
-	Name   string
+	UID          uuid.UUID
+	Secret       uuid.UUID
+	Owner        uuid.UUID
+	Name         string
+	RedirectURIs []string
+	IsPublic     bool
+	Scopes       []string
+	Lifetimes    TokenLifetimes
 }
 
 // AppInfo describes third-party app public info
 type AppInfo struct {
-	Owner uuid.UUID
-	Name  string
+	Owner    uuid.UUID
+	Name     string
+	IsPublic bool
+	// Scopes lists scopes app is allowed to request
+	Scopes []string
+	// Lifetimes overrides lifetimes of tokens issued to app
+	Lifetimes TokenLifetimes
 }
 
 type datastore interface {
@@ -46,11 +51,13 @@ type datastore interface {
 	create(string, string) (*User, error)
 	update(uuid.UUID, string) error
 	delete(uuid.UUID) error
-	checkPassword(uuid.UUID, string) (bool, error)
+	getPasswordHash(uuid.UUID) (string, error)
+	replacePasswordHash(uuid.UUID, string, string) error
 	getUIDByUsername(string) (uuid.UUID, error)
-	createApp(uuid.UUID, string) (*App, error)
+	createApp(uuid.UUID, string, []string, bool, []string, TokenLifetimes) (*App, error)
 	getAppInfo(uuid.UUID) (*AppInfo, error)
 	isValidAppCredentials(uuid.UUID, uuid.UUID) (bool, error)
+	isRegisteredRedirectURI(uuid.UUID, string) (bool, error)
 }
 
 type db struct {
@@ -62,17 +69,8 @@ func newDB(connString string) (*db, error) {
 	return &db{postgres}, err
 }
 
-func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
-	return string(bytes), err
-}
-
-func checkPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
-
 func (db *db) getUserInfo(uid uuid.UUID) (*User, error) {
+	defer observeDuration(dbQueryDuration.WithLabelValues("get_user_info"), time.Now())
 	query := "SELECT username, is_admin FROM users WHERE uid=$1"
 	row := db.QueryRow(query, uid.String())
 	result := new(User)
@@ -87,20 +85,21 @@ func (db *db) getUserInfo(uid uuid.UUID) (*User, error) {
 	}
 }
 
-func (db *db) create(username, password string) (*User, error) {
+func (db *db) create(username, passwordHash string) (*User, error) {
 	user := new(User)
 
-	query := "INSERT INTO users (uid, username, password_hash) VALUES ($1, $2, $3)"
-	uid := uuid.New()
-	passwordHash, err := hashPassword(password)
+	query := "INSERT INTO users (uid, username, username_normalized, password_hash) VALUES ($1, $2, $3, $4)"
+	normalized, err := normalizeUsername(username)
 	if err != nil {
 		return nil, err
 	}
 
+	uid := uuid.New()
 	user.UID = uid
 	user.Username = username
 
-	result, err := db.Exec(query, user.UID.String(), username, passwordHash)
+	defer observeDuration(dbQueryDuration.WithLabelValues("create_user"), time.Now())
+	result, err := db.Exec(query, user.UID.String(), username, normalized, passwordHash)
 	if err != nil {
 		// 23505 is a code for unique constraint violation
 		if e, ok := err.(*pq.Error); ok && e.Code == "23505" {
@@ -122,13 +121,9 @@ func (db *db) create(username, password string) (*User, error) {
 	return user, nil
 }
 
-func (db *db) update(uid uuid.UUID, newPassword string) error {
+func (db *db) update(uid uuid.UUID, passwordHash string) error {
 	query := "UPDATE users SET password_hash=$1 WHERE uid=$2"
-	passwordHash, err := hashPassword(newPassword)
-	if err != nil {
-		return err
-	}
-
+	defer observeDuration(dbQueryDuration.WithLabelValues("update_user"), time.Now())
 	result, err := db.Exec(query, passwordHash, uid.String())
 	if err != nil {
 		return err
@@ -147,6 +142,7 @@ func (db *db) update(uid uuid.UUID, newPassword string) error {
 }
 
 func (db *db) delete(uid uuid.UUID) error {
+	defer observeDuration(dbQueryDuration.WithLabelValues("delete_user"), time.Now())
 	query := "DELETE FROM users WHERE uid=$1"
 	result, err := db.Exec(query, uid.String())
 	if err != nil {
@@ -165,23 +161,41 @@ func (db *db) delete(uid uuid.UUID) error {
 	return nil
 }
 
-func (db *db) checkPassword(uid uuid.UUID, password string) (bool, error) {
+func (db *db) getPasswordHash(uid uuid.UUID) (string, error) {
+	defer observeDuration(dbQueryDuration.WithLabelValues("get_password_hash"), time.Now())
 	query := "SELECT password_hash FROM users WHERE uid=$1"
 	row := db.QueryRow(query, uid.String())
-	var passwordFromDB string
-	switch err := row.Scan(&passwordFromDB); err {
+	var result string
+	switch err := row.Scan(&result); err {
 	case nil:
-		return checkPasswordHash(password, passwordFromDB), nil
+		return result, nil
 	case sql.ErrNoRows:
-		return false, errNotFound
+		return "", errNotFound
 	default:
-		return false, err
+		return "", err
 	}
 }
 
+// replacePasswordHash updates password hash only if it was not changed since oldHash was read
+func (db *db) replacePasswordHash(uid uuid.UUID, oldHash, newHash string) error {
+	defer observeDuration(dbQueryDuration.WithLabelValues("replace_password_hash"), time.Now())
+	query := "UPDATE users SET password_hash=$1 WHERE uid=$2 AND password_hash=$3"
+	_, err := db.Exec(query, newHash, uid.String(), oldHash)
+	return err
+}
+
+// getUIDByUsername looks user up by normalized username, so it ignores case and Unicode representation.
+// Users registered before usernames were normalized may have no normalized username, they are looked up by exact name
 func (db *db) getUIDByUsername(username string) (uuid.UUID, error) {
-	query := "SELECT uid FROM users WHERE username=$1"
-	row := db.QueryRow(query, username)
+	var normalized sql.NullString
+	if s, err := normalizeUsername(username); err == nil {
+		normalized = sql.NullString{String: s, Valid: true}
+	}
+
+	defer observeDuration(dbQueryDuration.WithLabelValues("get_uid_by_username"), time.Now())
+	// exact match of legacy username goes first, otherwise it could be shadowed by a normalized one
+	query := "SELECT uid FROM users WHERE (username_normalized IS NULL AND username=$1) OR username_normalized=$2 ORDER BY username_normalized NULLS FIRST LIMIT 1"
+	row := db.QueryRow(query, username, normalized)
 	var uid string
 	switch err := row.Scan(&uid); err {
 	case nil:
@@ -193,12 +207,21 @@ func (db *db) getUIDByUsername(username string) (uuid.UUID, error) {
 	}
 }
 
-func (db *db) createApp(owner uuid.UUID, name string) (*App, error) {
-	query := "INSERT INTO apps (uid, secret, owner, name) VALUES ($1, $2, $3, $4)"
+func (db *db) createApp(owner uuid.UUID, name string, redirectURIs []string, isPublic bool, scopes []string, lifetimes TokenLifetimes) (*App, error) {
+	query := "INSERT INTO apps (uid, secret, owner, name, redirect_uris, is_public, scopes, access_token_lifetime, refresh_token_lifetime, session_lifetime) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
 	uid := uuid.New()
 	secret := uuid.New()
+	if redirectURIs == nil {
+		redirectURIs = []string{}
+	}
 
-	result, err := db.Exec(query, uid, secret, owner.String(), name)
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	defer observeDuration(dbQueryDuration.WithLabelValues("create_app"), time.Now())
+	result, err := db.Exec(query, uid, secret, owner.String(), name, pq.Array(redirectURIs), isPublic, pq.Array(scopes),
+		int64(lifetimes.AccessToken.Seconds()), int64(lifetimes.RefreshToken.Seconds()), int64(lifetimes.Session.Seconds()))
 	if err != nil {
 		return nil, err
 	}
@@ -217,16 +240,26 @@ func (db *db) createApp(owner uuid.UUID, name string) (*App, error) {
 	app.Secret = secret
 	app.Owner = owner
 	app.Name = name
+	app.RedirectURIs = redirectURIs
+	app.IsPublic = isPublic
+	app.Scopes = scopes
+	app.Lifetimes = lifetimes
 
 	return app, nil
 }
 
 func (db *db) getAppInfo(appID uuid.UUID) (*AppInfo, error) {
-	query := "SELECT owner, name FROM apps WHERE uid=$1"
+	defer observeDuration(dbQueryDuration.WithLabelValues("get_app_info"), time.Now())
+	query := "SELECT owner, name, is_public, scopes, access_token_lifetime, refresh_token_lifetime, session_lifetime FROM apps WHERE uid=$1"
 	row := db.QueryRow(query, appID.String())
 	result := new(AppInfo)
-	switch err := row.Scan(&result.Owner, &result.Name); err {
+	var accessTokenLifetime, refreshTokenLifetime, sessionLifetime int64
+	switch err := row.Scan(&result.Owner, &result.Name, &result.IsPublic, pq.Array(&result.Scopes),
+		&accessTokenLifetime, &refreshTokenLifetime, &sessionLifetime); err {
 	case nil:
+		result.Lifetimes.AccessToken = time.Duration(accessTokenLifetime) * time.Second
+		result.Lifetimes.RefreshToken = time.Duration(refreshTokenLifetime) * time.Second
+		result.Lifetimes.Session = time.Duration(sessionLifetime) * time.Second
 		return result, nil
 	case sql.ErrNoRows:
 		return nil, errNotFound
@@ -236,6 +269,7 @@ func (db *db) getAppInfo(appID uuid.UUID) (*AppInfo, error) {
 }
 
 func (db *db) isValidAppCredentials(appID, appSecret uuid.UUID) (bool, error) {
+	defer observeDuration(dbQueryDuration.WithLabelValues("check_app_credentials"), time.Now())
 	query := "SELECT EXISTS(SELECT 1 FROM apps WHERE uid=$1 AND secret=$2)"
 	row := db.QueryRow(query, appID.String(), appSecret.String())
 	var result bool
@@ -248,3 +282,18 @@ func (db *db) isValidAppCredentials(appID, appSecret uuid.UUID) (bool, error) {
 		return false, err
 	}
 }
+
+func (db *db) isRegisteredRedirectURI(appID uuid.UUID, redirectURI string) (bool, error) {
+	defer observeDuration(dbQueryDuration.WithLabelValues("check_redirect_uri"), time.Now())
+	query := "SELECT $2 = ANY(redirect_uris) FROM apps WHERE uid=$1"
+	row := db.QueryRow(query, appID.String(), redirectURI)
+	var result bool
+	switch err := row.Scan(&result); err {
+	case nil:
+		return result, nil
+	case sql.ErrNoRows:
+		return false, errNotFound
+	default:
+		return false, err
+	}
+}
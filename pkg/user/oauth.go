@@ -0,0 +1,129 @@
+package user
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	codeChallengePlain = "plain"
+	codeChallengeS256  = "S256"
+)
+
+// oauthCodeInfo describes oauth code saved to storage
+type oauthCodeInfo struct {
+	UID                 string   `json:"uid"`
+	RedirectURI         string   `json:"redirect_uri"`
+	CodeChallenge       string   `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string   `json:"code_challenge_method,omitempty"`
+	Scopes              []string `json:"scopes"`
+	Nonce               string   `json:"nonce,omitempty"`
+	// AuthTime is a unix time when user entered credentials
+	AuthTime int64 `json:"auth_time"`
+}
+
+func oauthCodeKey(appUID, code string) string {
+	return appUID + code
+}
+
+// isValidRedirectURI checks that redirect uri is an absolute uri without fragment as required by RFC 6749
+func isValidRedirectURI(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	return err == nil && u.IsAbs() && u.Fragment == ""
+}
+
+// isValidCodeChallenge checks PKCE code challenge as described in RFC 7636, challenge consists of the same
+// unreserved characters as verifier
+func isValidCodeChallenge(challenge, method string) bool {
+	if method != codeChallengePlain && method != codeChallengeS256 {
+		return false
+	}
+
+	if len(challenge) < 43 || len(challenge) > 128 {
+		return false
+	}
+
+	for _, c := range challenge {
+		if !isCodeVerifierChar(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCodeVerifierChar checks whether c is an unreserved character allowed in PKCE code verifier
+func isCodeVerifierChar(c rune) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// verifyCodeChallenge checks that code verifier matches challenge stored with oauth code
+func (info *oauthCodeInfo) verifyCodeChallenge(verifier string) bool {
+	expected := verifier
+	if info.CodeChallengeMethod == codeChallengeS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(info.CodeChallenge)) == 1
+}
+
+// issueOAuthCode creates oauth code for app and saves it to storage
+func (s *Server) issueOAuthCode(appUID string, info *oauthCodeInfo, expiration time.Duration) (string, error) {
+	value, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	code := uuid.New().String()
+	err = s.oauthCodeStorage.Set(oauthCodeKey(appUID, code), string(value), expiration)
+	if err != nil {
+		return "", err
+	}
+
+	tokensIssued.WithLabelValues("oauth_code").Inc()
+	return code, nil
+}
+
+// consumeOAuthCode returns oauth code info and deletes code so it can be used only once
+func (s *Server) consumeOAuthCode(appUID, code string) (*oauthCodeInfo, error) {
+	value, err := s.oauthCodeStorage.Take(oauthCodeKey(appUID, code))
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(oauthCodeInfo)
+	if err := json.Unmarshal([]byte(value), info); err != nil {
+		return nil, errTokenNotFound
+	}
+
+	return info, nil
+}
+
+// issueIDToken creates ID token for app with claims about user who authorized oauth code
+func (s *Server) issueIDToken(appUID string, code *oauthCodeInfo, expiration time.Duration) (string, error) {
+	uid, err := uuid.Parse(code.UID)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.db.getUserInfo(uid)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.signer.signIDToken(appUID, user, code.Scopes, code.Nonce, time.Unix(code.AuthTime, 0), s.signer.limitLifetime(expiration))
+	if err != nil {
+		return "", err
+	}
+
+	tokensIssued.WithLabelValues("id_token").Inc()
+	return token, nil
+}
@@ -0,0 +1,202 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/andreymgn/RSOI-user/pkg/user/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testRedirectURI = "https://app.example.com/callback"
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	verifier := strings.Repeat("v", 43)
+
+	tests := []struct {
+		name     string
+		info     oauthCodeInfo
+		verifier string
+		want     bool
+	}{
+		{"plain", oauthCodeInfo{CodeChallenge: verifier, CodeChallengeMethod: codeChallengePlain}, verifier, true},
+		{"plain mismatch", oauthCodeInfo{CodeChallenge: verifier, CodeChallengeMethod: codeChallengePlain}, verifier + "x", false},
+		{"S256", oauthCodeInfo{CodeChallenge: s256(verifier), CodeChallengeMethod: codeChallengeS256}, verifier, true},
+		{"S256 mismatch", oauthCodeInfo{CodeChallenge: s256(verifier), CodeChallengeMethod: codeChallengeS256}, verifier + "x", false},
+		{"S256 challenge as verifier", oauthCodeInfo{CodeChallenge: s256(verifier), CodeChallengeMethod: codeChallengeS256}, s256(verifier), false},
+		{"empty verifier", oauthCodeInfo{CodeChallenge: s256(verifier), CodeChallengeMethod: codeChallengeS256}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.verifyCodeChallenge(tt.verifier); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOAuthCodeChecks(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	addUser(t, s, db, "user", "password", false)
+	confidential := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	public := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+	challenge := s256(strings.Repeat("v", 43))
+
+	tests := []struct {
+		name string
+		req  pb.GetOAuthCodeRequest
+		want codes.Code
+	}{
+		{"registered redirect uri", pb.GetOAuthCodeRequest{AppUid: confidential.UID.String(), RedirectUri: testRedirectURI}, codes.OK},
+		{"public app with challenge", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI, CodeChallenge: challenge, CodeChallengeMethod: codeChallengeS256}, codes.OK},
+		{"public app without challenge", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI}, codes.InvalidArgument},
+		{"unknown challenge method", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI, CodeChallenge: challenge, CodeChallengeMethod: "S512"}, codes.InvalidArgument},
+		{"short challenge", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI, CodeChallenge: "short", CodeChallengeMethod: codeChallengePlain}, codes.InvalidArgument},
+		{"challenge with reserved characters", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI, CodeChallenge: strings.Repeat("v/+=", 11), CodeChallengeMethod: codeChallengePlain}, codes.InvalidArgument},
+		{"challenge with non-ascii characters", pb.GetOAuthCodeRequest{AppUid: public.UID.String(), RedirectUri: testRedirectURI, CodeChallenge: strings.Repeat("в", 43), CodeChallengeMethod: codeChallengePlain}, codes.InvalidArgument},
+		{"unregistered redirect uri", pb.GetOAuthCodeRequest{AppUid: confidential.UID.String(), RedirectUri: "https://evil.example.com/callback"}, codes.InvalidArgument},
+		{"redirect uri with extra path", pb.GetOAuthCodeRequest{AppUid: confidential.UID.String(), RedirectUri: testRedirectURI + "/x"}, codes.InvalidArgument},
+		{"unknown app", pb.GetOAuthCodeRequest{AppUid: uuid.New().String(), RedirectUri: testRedirectURI}, codes.NotFound},
+		{"invalid app uid", pb.GetOAuthCodeRequest{AppUid: "app", RedirectUri: testRedirectURI}, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Username = "user"
+			req.Password = "password"
+			_, err := s.GetOAuthCode(context.Background(), &req)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("GetOAuthCode() code = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetTokenFromCodeChecks(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	addUser(t, s, db, "user", "password", false)
+	app := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+	other := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+	verifier := strings.Repeat("v", 43)
+
+	tests := []struct {
+		name        string
+		appUID      uuid.UUID
+		redirectURI string
+		verifier    string
+		want        error
+	}{
+		{"valid", app.UID, testRedirectURI, verifier, nil},
+		{"wrong verifier", app.UID, testRedirectURI, verifier + "x", statusInvalidVerifier},
+		{"missing verifier", app.UID, testRedirectURI, "", statusInvalidVerifier},
+		{"wrong redirect uri", app.UID, testRedirectURI + "/x", verifier, statusInvalidRedirect},
+		{"code of another app", other.UID, testRedirectURI, verifier, statusInvalidUserToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := s.GetOAuthCode(context.Background(), &pb.GetOAuthCodeRequest{
+				AppUid:              app.UID.String(),
+				RedirectUri:         testRedirectURI,
+				Username:            "user",
+				Password:            "password",
+				CodeChallenge:       s256(verifier),
+				CodeChallengeMethod: codeChallengeS256,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &pb.GetTokenFromCodeRequest{
+				AppUid:       tt.appUID.String(),
+				Code:         code.Code,
+				RedirectUri:  tt.redirectURI,
+				CodeVerifier: tt.verifier,
+			}
+			if _, err := s.GetTokenFromCode(context.Background(), req); err != tt.want {
+				t.Fatalf("GetTokenFromCode() = %v, want %v", err, tt.want)
+			}
+
+			// code presented by its own app is used up even if exchange failed
+			if tt.appUID != app.UID {
+				return
+			}
+
+			req.RedirectUri = testRedirectURI
+			req.CodeVerifier = verifier
+			if _, err := s.GetTokenFromCode(context.Background(), req); err != statusInvalidUserToken {
+				t.Errorf("reused code: got %v, want %v", err, statusInvalidUserToken)
+			}
+		})
+	}
+}
+
+func TestGetTokenFromCodeConcurrentExchange(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	addUser(t, s, db, "user", "password", false)
+	app := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+	verifier := strings.Repeat("v", 43)
+
+	code, err := s.GetOAuthCode(context.Background(), &pb.GetOAuthCodeRequest{
+		AppUid:              app.UID.String(),
+		RedirectUri:         testRedirectURI,
+		Username:            "user",
+		Password:            "password",
+		CodeChallenge:       s256(verifier),
+		CodeChallengeMethod: codeChallengeS256,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.GetTokenFromCodeRequest{
+		AppUid:       app.UID.String(),
+		Code:         code.Code,
+		RedirectUri:  testRedirectURI,
+		CodeVerifier: verifier,
+	}
+
+	const exchanges = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, exchanges)
+	for i := 0; i < exchanges; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.GetTokenFromCode(context.Background(), req)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if err != statusInvalidUserToken {
+			t.Errorf("GetTokenFromCode() = %v, want %v", err, statusInvalidUserToken)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Errorf("code was exchanged %d times, want once", succeeded)
+	}
+}
@@ -0,0 +1,219 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultBcryptCost is a cost of bcrypt hashes used unless other hasher is configured
+	DefaultBcryptCost = 10
+
+	// DefaultArgon2Memory is a memory in KiB used by argon2id, defaults follow RFC 9106 recommendations
+	DefaultArgon2Memory = 64 * 1024
+	// DefaultArgon2Iterations is a number of passes over memory used by argon2id
+	DefaultArgon2Iterations = 3
+	// DefaultArgon2Parallelism is a number of threads used by argon2id
+	DefaultArgon2Parallelism = 4
+	// MaxArgon2Memory is a maximal memory in KiB of argon2id hashes, stored hashes with more memory are rejected
+	// so corrupted hash can not exhaust memory on login
+	MaxArgon2Memory = 1024 * 1024
+	// MaxArgon2Iterations is a maximal number of passes of argon2id hashes
+	MaxArgon2Iterations = 64
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	argon2Prefix     = "$argon2id$"
+)
+
+var (
+	errUnknownHashFormat = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes passwords, algorithm and its parameters are stored in the hash itself
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether hash was made by other algorithm or with other parameters than this hasher uses
+	NeedsRehash(hash string) bool
+}
+
+// BcryptHasher hashes passwords with bcrypt
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash returns bcrypt hash of password
+func (h BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(bytes), err
+}
+
+// NeedsRehash reports whether hash is not a bcrypt hash of the same cost
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher hashes passwords with argon2id, hashes are encoded in PHC string format
+type Argon2idHasher struct {
+	// Memory is measured in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// argon2Params are parameters of argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// Hash returns argon2id hash of password with random salt
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// NeedsRehash reports whether hash is not an argon2id hash with the same parameters
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	params, err := parseArgon2Hash(hash)
+	return err != nil ||
+		params.memory != h.Memory ||
+		params.iterations != h.Iterations ||
+		params.parallelism != h.Parallelism ||
+		len(params.salt) != argon2SaltLength ||
+		len(params.key) != argon2KeyLength
+}
+
+// validArgon2Params checks that argon2id can compute hash with parameters in reasonable time and memory,
+// argon2 needs at least 8 KiB of memory per thread
+func validArgon2Params(memory, iterations uint32, parallelism uint8) bool {
+	return parallelism > 0 &&
+		memory >= 8*uint32(parallelism) && memory <= MaxArgon2Memory &&
+		iterations > 0 && iterations <= MaxArgon2Iterations
+}
+
+// parseArgon2Hash parses argon2id hash encoded in PHC string format
+func parseArgon2Hash(hash string) (*argon2Params, error) {
+	if !strings.HasPrefix(hash, argon2Prefix) {
+		return nil, errUnknownHashFormat
+	}
+
+	// "", "argon2id", "v=19", "m=65536,t=3,p=4", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, errUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errUnknownHashFormat
+	}
+
+	params := new(argon2Params)
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil || !validArgon2Params(params.memory, params.iterations, params.parallelism) {
+		return nil, errUnknownHashFormat
+	}
+
+	params.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errUnknownHashFormat
+	}
+
+	params.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(params.key) == 0 {
+		return nil, errUnknownHashFormat
+	}
+
+	return params, nil
+}
+
+// verifyPassword checks password against hash made by any supported algorithm
+func verifyPassword(password, hash string) (bool, error) {
+	defer observeDuration(passwordHashDuration.WithLabelValues("compare"), time.Now())
+	if strings.HasPrefix(hash, argon2Prefix) {
+		params, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false, err
+		}
+
+		key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+		return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+	}
+
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return false, errUnknownHashFormat
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, nil
+}
+
+// hashPassword hashes password with configured hasher
+func (s *Server) hashPassword(password string) (string, error) {
+	defer observeDuration(passwordHashDuration.WithLabelValues("hash"), time.Now())
+	return s.hasher.Hash(password)
+}
+
+// makeDummyHash hashes random password with configured hasher. Comparing password with it takes as long as with
+// hash of existing user
+func (s *Server) makeDummyHash() error {
+	password := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		return fmt.Errorf("failed to generate dummy password: %v", err)
+	}
+
+	hash, err := s.hashPassword(base64.RawStdEncoding.EncodeToString(password))
+	if err != nil {
+		return fmt.Errorf("failed to hash dummy password: %v", err)
+	}
+
+	s.dummyHash = hash
+	return nil
+}
+
+// checkPassword checks password of user. On success password hash made with outdated algorithm or parameters
+// is replaced with a new one, failure to do so does not prevent user from logging in
+func (s *Server) checkPassword(uid uuid.UUID, password string) (bool, error) {
+	hash, err := s.db.getPasswordHash(uid)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := verifyPassword(password, hash)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if s.hasher.NeedsRehash(hash) {
+		newHash, err := s.hashPassword(password)
+		if err == nil {
+			err = s.db.replacePasswordHash(uid, hash, newHash)
+		}
+
+		if err != nil {
+			log.Printf("failed to rehash password of user %s: %v", uid, err)
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package user
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgon2Hash(t *testing.T) {
+	const salt, key = "c2FsdHNhbHRzYWx0c2FsdA", "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+	tests := []struct {
+		name    string
+		params  string
+		wantErr error
+	}{
+		{"default parameters", "m=65536,t=3,p=4", nil},
+		{"zero iterations", "m=65536,t=0,p=4", errUnknownHashFormat},
+		{"too many iterations", "m=65536,t=1000000,p=4", errUnknownHashFormat},
+		{"zero parallelism", "m=65536,t=3,p=0", errUnknownHashFormat},
+		{"too little memory per thread", "m=16,t=3,p=4", errUnknownHashFormat},
+		{"too much memory", "m=4294967295,t=3,p=4", errUnknownHashFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := argon2Prefix + "v=19$" + tt.params + "$" + salt + "$" + key
+			if _, err := parseArgon2Hash(hash); err != tt.wantErr {
+				t.Errorf("parseArgon2Hash(%q) error = %v, want %v", hash, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordRejectsUnsafeArgon2Hash(t *testing.T) {
+	hash, err := Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1}.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := verifyPassword("correct horse", hash); !ok || err != nil {
+		t.Fatalf("verifyPassword() = %v, %v, want true", ok, err)
+	}
+
+	unsafe := []string{"m=64,t=0,p=1", "m=64,t=1,p=0", "m=4294967295,t=1,p=1"}
+	for _, params := range unsafe {
+		corrupted := strings.Replace(hash, "m=64,t=1,p=1", params, 1)
+		if _, err := verifyPassword("correct horse", corrupted); err != errUnknownHashFormat {
+			t.Errorf("verifyPassword() with %s = %v, want %v", params, err, errUnknownHashFormat)
+		}
+	}
+}
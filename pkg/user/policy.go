@@ -0,0 +1,130 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMinPasswordLength is a minimal number of characters in password
+	DefaultMinPasswordLength = 8
+	// MaxBcryptPasswordLength is a number of bytes of password used by bcrypt, the rest is silently ignored
+	MaxBcryptPasswordLength = 72
+
+	// minUsernameCheckLength is a length of username below which its presence in password is not checked,
+	// otherwise very short usernames would forbid too many passwords
+	minUsernameCheckLength = 3
+)
+
+// PasswordPolicy describes requirements to new passwords, zero fields disable corresponding checks
+type PasswordPolicy struct {
+	// MinLength is measured in characters
+	MinLength int
+	// MaxLength is measured in bytes, it should not exceed MaxBcryptPasswordLength when bcrypt is used
+	MaxLength int
+	// MinCharacterClasses is a number of classes out of lowercase letters, uppercase letters, digits and other
+	// characters password must contain
+	MinCharacterClasses int
+	// DisallowUsername rejects passwords containing username regardless of case
+	DisallowUsername bool
+	// DisallowCommon rejects passwords from bundled list of commonly used passwords regardless of case
+	DisallowCommon bool
+}
+
+// DefaultPasswordPolicy is a policy used unless other policy is configured
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:        DefaultMinPasswordLength,
+	MaxLength:        MaxBcryptPasswordLength,
+	DisallowUsername: true,
+	DisallowCommon:   true,
+}
+
+// characterClasses returns number of character classes used in password
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	result := 0
+	for _, used := range []bool{lower, upper, digit, other} {
+		if used {
+			result++
+		}
+	}
+
+	return result
+}
+
+// violations returns descriptions of all rules password of user violates
+func (p PasswordPolicy) violations(username, password string) []string {
+	if password == "" {
+		return []string{"password is empty"}
+	}
+
+	var result []string
+	if p.MinLength > 0 && utf8.RuneCountInString(password) < p.MinLength {
+		result = append(result, fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		result = append(result, fmt.Sprintf("password must be at most %d bytes long", p.MaxLength))
+	}
+
+	if p.MinCharacterClasses > 0 && characterClasses(password) < p.MinCharacterClasses {
+		result = append(result, fmt.Sprintf("password must contain at least %d of lowercase letters, uppercase letters, digits and other characters", p.MinCharacterClasses))
+	}
+
+	if p.DisallowUsername && len(username) >= minUsernameCheckLength && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		result = append(result, "password must not contain username")
+	}
+
+	if p.DisallowCommon && commonPasswords[strings.ToLower(password)] {
+		result = append(result, "password is too common")
+	}
+
+	return result
+}
+
+// check returns InvalidArgument status listing violated rules in BadRequest details or nil if password is acceptable
+func (p PasswordPolicy) check(username, password string) error {
+	violations := p.violations(username, password)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return invalidArgument("password", "password does not satisfy policy", violations)
+}
+
+// invalidArgument returns InvalidArgument status with violations of rules for field listed in BadRequest details
+func invalidArgument(field, message string, violations []string) error {
+	details := new(errdetails.BadRequest)
+	for _, description := range violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: description,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, message+": "+strings.Join(violations, "; "))
+	if detailed, err := st.WithDetails(details); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
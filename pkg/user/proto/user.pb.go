@@ -34,7 +34,7 @@ func (m *GetUserInfoRequest) Reset()         { *m = GetUserInfoRequest{} }
 func (m *GetUserInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*GetUserInfoRequest) ProtoMessage()    {}
 func (*GetUserInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{0}
+	return fileDescriptor_user_c2e94eab3735f647, []int{0}
 }
 func (m *GetUserInfoRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetUserInfoRequest.Unmarshal(m, b)
@@ -74,7 +74,7 @@ func (m *UserInfo) Reset()         { *m = UserInfo{} }
 func (m *UserInfo) String() string { return proto.CompactTextString(m) }
 func (*UserInfo) ProtoMessage()    {}
 func (*UserInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{1}
+	return fileDescriptor_user_c2e94eab3735f647, []int{1}
 }
 func (m *UserInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UserInfo.Unmarshal(m, b)
@@ -128,7 +128,7 @@ func (m *CreateUserRequest) Reset()         { *m = CreateUserRequest{} }
 func (m *CreateUserRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateUserRequest) ProtoMessage()    {}
 func (*CreateUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{2}
+	return fileDescriptor_user_c2e94eab3735f647, []int{2}
 }
 func (m *CreateUserRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateUserRequest.Unmarshal(m, b)
@@ -182,7 +182,7 @@ func (m *UpdateUserRequest) Reset()         { *m = UpdateUserRequest{} }
 func (m *UpdateUserRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateUserRequest) ProtoMessage()    {}
 func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{3}
+	return fileDescriptor_user_c2e94eab3735f647, []int{3}
 }
 func (m *UpdateUserRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateUserRequest.Unmarshal(m, b)
@@ -233,7 +233,7 @@ func (m *UpdateUserResponse) Reset()         { *m = UpdateUserResponse{} }
 func (m *UpdateUserResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateUserResponse) ProtoMessage()    {}
 func (*UpdateUserResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{4}
+	return fileDescriptor_user_c2e94eab3735f647, []int{4}
 }
 func (m *UpdateUserResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateUserResponse.Unmarshal(m, b)
@@ -265,7 +265,7 @@ func (m *DeleteUserRequest) Reset()         { *m = DeleteUserRequest{} }
 func (m *DeleteUserRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteUserRequest) ProtoMessage()    {}
 func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{5}
+	return fileDescriptor_user_c2e94eab3735f647, []int{5}
 }
 func (m *DeleteUserRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteUserRequest.Unmarshal(m, b)
@@ -309,7 +309,7 @@ func (m *DeleteUserResponse) Reset()         { *m = DeleteUserResponse{} }
 func (m *DeleteUserResponse) String() string { return proto.CompactTextString(m) }
 func (*DeleteUserResponse) ProtoMessage()    {}
 func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{6}
+	return fileDescriptor_user_c2e94eab3735f647, []int{6}
 }
 func (m *DeleteUserResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteUserResponse.Unmarshal(m, b)
@@ -341,7 +341,7 @@ func (m *GetTokenRequest) Reset()         { *m = GetTokenRequest{} }
 func (m *GetTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*GetTokenRequest) ProtoMessage()    {}
 func (*GetTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{7}
+	return fileDescriptor_user_c2e94eab3735f647, []int{7}
 }
 func (m *GetTokenRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetTokenRequest.Unmarshal(m, b)
@@ -378,6 +378,7 @@ func (m *GetTokenRequest) GetPassword() string {
 type GetAccessTokenResponse struct {
 	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	Uid                  string   `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	ExpiresIn            int64    `protobuf:"varint,3,opt,name=expiresIn,proto3" json:"expiresIn,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -387,7 +388,7 @@ func (m *GetAccessTokenResponse) Reset()         { *m = GetAccessTokenResponse{}
 func (m *GetAccessTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*GetAccessTokenResponse) ProtoMessage()    {}
 func (*GetAccessTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{8}
+	return fileDescriptor_user_c2e94eab3735f647, []int{8}
 }
 func (m *GetAccessTokenResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetAccessTokenResponse.Unmarshal(m, b)
@@ -421,8 +422,16 @@ func (m *GetAccessTokenResponse) GetUid() string {
 	return ""
 }
 
+func (m *GetAccessTokenResponse) GetExpiresIn() int64 {
+	if m != nil {
+		return m.ExpiresIn
+	}
+	return 0
+}
+
 type GetUserByAccessTokenRequest struct {
 	UserToken            string   `protobuf:"bytes,1,opt,name=userToken,proto3" json:"userToken,omitempty"`
+	ExtendExpiration     bool     `protobuf:"varint,2,opt,name=extendExpiration,proto3" json:"extendExpiration,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -432,7 +441,7 @@ func (m *GetUserByAccessTokenRequest) Reset()         { *m = GetUserByAccessToke
 func (m *GetUserByAccessTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*GetUserByAccessTokenRequest) ProtoMessage()    {}
 func (*GetUserByAccessTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{9}
+	return fileDescriptor_user_c2e94eab3735f647, []int{9}
 }
 func (m *GetUserByAccessTokenRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetUserByAccessTokenRequest.Unmarshal(m, b)
@@ -459,8 +468,17 @@ func (m *GetUserByAccessTokenRequest) GetUserToken() string {
 	return ""
 }
 
+func (m *GetUserByAccessTokenRequest) GetExtendExpiration() bool {
+	if m != nil {
+		return m.ExtendExpiration
+	}
+	return false
+}
+
 type GetUserByAccessTokenResponse struct {
 	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Scopes               []string `protobuf:"bytes,2,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	SubjectType          string   `protobuf:"bytes,3,opt,name=subjectType,proto3" json:"subjectType,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -470,7 +488,7 @@ func (m *GetUserByAccessTokenResponse) Reset()         { *m = GetUserByAccessTok
 func (m *GetUserByAccessTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*GetUserByAccessTokenResponse) ProtoMessage()    {}
 func (*GetUserByAccessTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{10}
+	return fileDescriptor_user_c2e94eab3735f647, []int{10}
 }
 func (m *GetUserByAccessTokenResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetUserByAccessTokenResponse.Unmarshal(m, b)
@@ -497,6 +515,20 @@ func (m *GetUserByAccessTokenResponse) GetUid() string {
 	return ""
 }
 
+func (m *GetUserByAccessTokenResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *GetUserByAccessTokenResponse) GetSubjectType() string {
+	if m != nil {
+		return m.SubjectType
+	}
+	return ""
+}
+
 type GetRefreshTokenResponse struct {
 	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -508,7 +540,7 @@ func (m *GetRefreshTokenResponse) Reset()         { *m = GetRefreshTokenResponse
 func (m *GetRefreshTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*GetRefreshTokenResponse) ProtoMessage()    {}
 func (*GetRefreshTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{11}
+	return fileDescriptor_user_c2e94eab3735f647, []int{11}
 }
 func (m *GetRefreshTokenResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetRefreshTokenResponse.Unmarshal(m, b)
@@ -546,7 +578,7 @@ func (m *RefreshAccessTokenRequest) Reset()         { *m = RefreshAccessTokenReq
 func (m *RefreshAccessTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*RefreshAccessTokenRequest) ProtoMessage()    {}
 func (*RefreshAccessTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{12}
+	return fileDescriptor_user_c2e94eab3735f647, []int{12}
 }
 func (m *RefreshAccessTokenRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RefreshAccessTokenRequest.Unmarshal(m, b)
@@ -576,6 +608,7 @@ func (m *RefreshAccessTokenRequest) GetRefreshToken() string {
 type RefreshAccessTokenResponse struct {
 	AccessToken          string   `protobuf:"bytes,1,opt,name=accessToken,proto3" json:"accessToken,omitempty"`
 	RefreshToken         string   `protobuf:"bytes,2,opt,name=refreshToken,proto3" json:"refreshToken,omitempty"`
+	ExpiresIn            int64    `protobuf:"varint,3,opt,name=expiresIn,proto3" json:"expiresIn,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -585,7 +618,7 @@ func (m *RefreshAccessTokenResponse) Reset()         { *m = RefreshAccessTokenRe
 func (m *RefreshAccessTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*RefreshAccessTokenResponse) ProtoMessage()    {}
 func (*RefreshAccessTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{13}
+	return fileDescriptor_user_c2e94eab3735f647, []int{13}
 }
 func (m *RefreshAccessTokenResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RefreshAccessTokenResponse.Unmarshal(m, b)
@@ -619,9 +652,22 @@ func (m *RefreshAccessTokenResponse) GetRefreshToken() string {
 	return ""
 }
 
+func (m *RefreshAccessTokenResponse) GetExpiresIn() int64 {
+	if m != nil {
+		return m.ExpiresIn
+	}
+	return 0
+}
+
 type CreateAppRequest struct {
 	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
 	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	RedirectUris         []string `protobuf:"bytes,3,rep,name=redirectUris,proto3" json:"redirectUris,omitempty"`
+	IsPublic             bool     `protobuf:"varint,4,opt,name=isPublic,proto3" json:"isPublic,omitempty"`
+	Scopes               []string `protobuf:"bytes,5,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	AccessTokenLifetime  int64    `protobuf:"varint,6,opt,name=accessTokenLifetime,proto3" json:"accessTokenLifetime,omitempty"`
+	RefreshTokenLifetime int64    `protobuf:"varint,7,opt,name=refreshTokenLifetime,proto3" json:"refreshTokenLifetime,omitempty"`
+	SessionLifetime      int64    `protobuf:"varint,8,opt,name=sessionLifetime,proto3" json:"sessionLifetime,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -631,7 +677,7 @@ func (m *CreateAppRequest) Reset()         { *m = CreateAppRequest{} }
 func (m *CreateAppRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateAppRequest) ProtoMessage()    {}
 func (*CreateAppRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{14}
+	return fileDescriptor_user_c2e94eab3735f647, []int{14}
 }
 func (m *CreateAppRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateAppRequest.Unmarshal(m, b)
@@ -665,6 +711,48 @@ func (m *CreateAppRequest) GetName() string {
 	return ""
 }
 
+func (m *CreateAppRequest) GetRedirectUris() []string {
+	if m != nil {
+		return m.RedirectUris
+	}
+	return nil
+}
+
+func (m *CreateAppRequest) GetIsPublic() bool {
+	if m != nil {
+		return m.IsPublic
+	}
+	return false
+}
+
+func (m *CreateAppRequest) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *CreateAppRequest) GetAccessTokenLifetime() int64 {
+	if m != nil {
+		return m.AccessTokenLifetime
+	}
+	return 0
+}
+
+func (m *CreateAppRequest) GetRefreshTokenLifetime() int64 {
+	if m != nil {
+		return m.RefreshTokenLifetime
+	}
+	return 0
+}
+
+func (m *CreateAppRequest) GetSessionLifetime() int64 {
+	if m != nil {
+		return m.SessionLifetime
+	}
+	return 0
+}
+
 type CreateAppResponse struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Secret               string   `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
@@ -677,7 +765,7 @@ func (m *CreateAppResponse) Reset()         { *m = CreateAppResponse{} }
 func (m *CreateAppResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateAppResponse) ProtoMessage()    {}
 func (*CreateAppResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{15}
+	return fileDescriptor_user_c2e94eab3735f647, []int{15}
 }
 func (m *CreateAppResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateAppResponse.Unmarshal(m, b)
@@ -722,7 +810,7 @@ func (m *GetAppInfoRequest) Reset()         { *m = GetAppInfoRequest{} }
 func (m *GetAppInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*GetAppInfoRequest) ProtoMessage()    {}
 func (*GetAppInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{16}
+	return fileDescriptor_user_c2e94eab3735f647, []int{16}
 }
 func (m *GetAppInfoRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetAppInfoRequest.Unmarshal(m, b)
@@ -752,6 +840,7 @@ func (m *GetAppInfoRequest) GetId() string {
 type GetAppInfoResponse struct {
 	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
 	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Scopes               []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -761,7 +850,7 @@ func (m *GetAppInfoResponse) Reset()         { *m = GetAppInfoResponse{} }
 func (m *GetAppInfoResponse) String() string { return proto.CompactTextString(m) }
 func (*GetAppInfoResponse) ProtoMessage()    {}
 func (*GetAppInfoResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{17}
+	return fileDescriptor_user_c2e94eab3735f647, []int{17}
 }
 func (m *GetAppInfoResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetAppInfoResponse.Unmarshal(m, b)
@@ -795,10 +884,22 @@ func (m *GetAppInfoResponse) GetName() string {
 	return ""
 }
 
+func (m *GetAppInfoResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
 type GetOAuthCodeRequest struct {
 	AppUid               string   `protobuf:"bytes,1,opt,name=appUid,proto3" json:"appUid,omitempty"`
 	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
 	Password             string   `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	RedirectUri          string   `protobuf:"bytes,4,opt,name=redirectUri,proto3" json:"redirectUri,omitempty"`
+	CodeChallenge        string   `protobuf:"bytes,5,opt,name=codeChallenge,proto3" json:"codeChallenge,omitempty"`
+	CodeChallengeMethod  string   `protobuf:"bytes,6,opt,name=codeChallengeMethod,proto3" json:"codeChallengeMethod,omitempty"`
+	Scopes               []string `protobuf:"bytes,7,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Nonce                string   `protobuf:"bytes,8,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -808,7 +909,7 @@ func (m *GetOAuthCodeRequest) Reset()         { *m = GetOAuthCodeRequest{} }
 func (m *GetOAuthCodeRequest) String() string { return proto.CompactTextString(m) }
 func (*GetOAuthCodeRequest) ProtoMessage()    {}
 func (*GetOAuthCodeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{18}
+	return fileDescriptor_user_c2e94eab3735f647, []int{18}
 }
 func (m *GetOAuthCodeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetOAuthCodeRequest.Unmarshal(m, b)
@@ -849,6 +950,41 @@ func (m *GetOAuthCodeRequest) GetPassword() string {
 	return ""
 }
 
+func (m *GetOAuthCodeRequest) GetRedirectUri() string {
+	if m != nil {
+		return m.RedirectUri
+	}
+	return ""
+}
+
+func (m *GetOAuthCodeRequest) GetCodeChallenge() string {
+	if m != nil {
+		return m.CodeChallenge
+	}
+	return ""
+}
+
+func (m *GetOAuthCodeRequest) GetCodeChallengeMethod() string {
+	if m != nil {
+		return m.CodeChallengeMethod
+	}
+	return ""
+}
+
+func (m *GetOAuthCodeRequest) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *GetOAuthCodeRequest) GetNonce() string {
+	if m != nil {
+		return m.Nonce
+	}
+	return ""
+}
+
 type GetOAuthCodeResponse struct {
 	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -860,7 +996,7 @@ func (m *GetOAuthCodeResponse) Reset()         { *m = GetOAuthCodeResponse{} }
 func (m *GetOAuthCodeResponse) String() string { return proto.CompactTextString(m) }
 func (*GetOAuthCodeResponse) ProtoMessage()    {}
 func (*GetOAuthCodeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{19}
+	return fileDescriptor_user_c2e94eab3735f647, []int{19}
 }
 func (m *GetOAuthCodeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetOAuthCodeResponse.Unmarshal(m, b)
@@ -891,6 +1027,8 @@ type GetTokenFromCodeRequest struct {
 	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	AppUid               string   `protobuf:"bytes,2,opt,name=appUid,proto3" json:"appUid,omitempty"`
 	AppSecret            string   `protobuf:"bytes,3,opt,name=appSecret,proto3" json:"appSecret,omitempty"`
+	RedirectUri          string   `protobuf:"bytes,4,opt,name=redirectUri,proto3" json:"redirectUri,omitempty"`
+	CodeVerifier         string   `protobuf:"bytes,5,opt,name=codeVerifier,proto3" json:"codeVerifier,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -900,7 +1038,7 @@ func (m *GetTokenFromCodeRequest) Reset()         { *m = GetTokenFromCodeRequest
 func (m *GetTokenFromCodeRequest) String() string { return proto.CompactTextString(m) }
 func (*GetTokenFromCodeRequest) ProtoMessage()    {}
 func (*GetTokenFromCodeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{20}
+	return fileDescriptor_user_c2e94eab3735f647, []int{20}
 }
 func (m *GetTokenFromCodeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetTokenFromCodeRequest.Unmarshal(m, b)
@@ -941,9 +1079,25 @@ func (m *GetTokenFromCodeRequest) GetAppSecret() string {
 	return ""
 }
 
+func (m *GetTokenFromCodeRequest) GetRedirectUri() string {
+	if m != nil {
+		return m.RedirectUri
+	}
+	return ""
+}
+
+func (m *GetTokenFromCodeRequest) GetCodeVerifier() string {
+	if m != nil {
+		return m.CodeVerifier
+	}
+	return ""
+}
+
 type GetTokenFromCodeResponse struct {
 	AccessToken          string   `protobuf:"bytes,1,opt,name=accessToken,proto3" json:"accessToken,omitempty"`
 	RefreshToken         string   `protobuf:"bytes,2,opt,name=refreshToken,proto3" json:"refreshToken,omitempty"`
+	IdToken              string   `protobuf:"bytes,3,opt,name=idToken,proto3" json:"idToken,omitempty"`
+	ExpiresIn            int64    `protobuf:"varint,4,opt,name=expiresIn,proto3" json:"expiresIn,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -953,7 +1107,7 @@ func (m *GetTokenFromCodeResponse) Reset()         { *m = GetTokenFromCodeRespon
 func (m *GetTokenFromCodeResponse) String() string { return proto.CompactTextString(m) }
 func (*GetTokenFromCodeResponse) ProtoMessage()    {}
 func (*GetTokenFromCodeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_d91e02009efc5113, []int{21}
+	return fileDescriptor_user_c2e94eab3735f647, []int{21}
 }
 func (m *GetTokenFromCodeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetTokenFromCodeResponse.Unmarshal(m, b)
@@ -987,162 +1141,817 @@ func (m *GetTokenFromCodeResponse) GetRefreshToken() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*GetUserInfoRequest)(nil), "user.GetUserInfoRequest")
-	proto.RegisterType((*UserInfo)(nil), "user.UserInfo")
-	proto.RegisterType((*CreateUserRequest)(nil), "user.CreateUserRequest")
-	proto.RegisterType((*UpdateUserRequest)(nil), "user.UpdateUserRequest")
-	proto.RegisterType((*UpdateUserResponse)(nil), "user.UpdateUserResponse")
-	proto.RegisterType((*DeleteUserRequest)(nil), "user.DeleteUserRequest")
-	proto.RegisterType((*DeleteUserResponse)(nil), "user.DeleteUserResponse")
-	proto.RegisterType((*GetTokenRequest)(nil), "user.GetTokenRequest")
-	proto.RegisterType((*GetAccessTokenResponse)(nil), "user.GetAccessTokenResponse")
-	proto.RegisterType((*GetUserByAccessTokenRequest)(nil), "user.GetUserByAccessTokenRequest")
-	proto.RegisterType((*GetUserByAccessTokenResponse)(nil), "user.GetUserByAccessTokenResponse")
-	proto.RegisterType((*GetRefreshTokenResponse)(nil), "user.GetRefreshTokenResponse")
-	proto.RegisterType((*RefreshAccessTokenRequest)(nil), "user.RefreshAccessTokenRequest")
-	proto.RegisterType((*RefreshAccessTokenResponse)(nil), "user.RefreshAccessTokenResponse")
-	proto.RegisterType((*CreateAppRequest)(nil), "user.CreateAppRequest")
-	proto.RegisterType((*CreateAppResponse)(nil), "user.CreateAppResponse")
-	proto.RegisterType((*GetAppInfoRequest)(nil), "user.GetAppInfoRequest")
-	proto.RegisterType((*GetAppInfoResponse)(nil), "user.GetAppInfoResponse")
-	proto.RegisterType((*GetOAuthCodeRequest)(nil), "user.GetOAuthCodeRequest")
-	proto.RegisterType((*GetOAuthCodeResponse)(nil), "user.GetOAuthCodeResponse")
-	proto.RegisterType((*GetTokenFromCodeRequest)(nil), "user.GetTokenFromCodeRequest")
-	proto.RegisterType((*GetTokenFromCodeResponse)(nil), "user.GetTokenFromCodeResponse")
+func (m *GetTokenFromCodeResponse) GetIdToken() string {
+	if m != nil {
+		return m.IdToken
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// UserClient is the client API for User service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type UserClient interface {
-	GetUserInfo(ctx context.Context, in *GetUserInfoRequest, opts ...grpc.CallOption) (*UserInfo, error)
-	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*UserInfo, error)
-	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
-	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
-	GetAccessToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetAccessTokenResponse, error)
-	GetRefreshToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetRefreshTokenResponse, error)
-	RefreshAccessToken(ctx context.Context, in *RefreshAccessTokenRequest, opts ...grpc.CallOption) (*RefreshAccessTokenResponse, error)
-	GetUserByAccessToken(ctx context.Context, in *GetUserByAccessTokenRequest, opts ...grpc.CallOption) (*GetUserByAccessTokenResponse, error)
-	CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error)
-	GetAppInfo(ctx context.Context, in *GetAppInfoRequest, opts ...grpc.CallOption) (*GetAppInfoResponse, error)
-	GetOAuthCode(ctx context.Context, in *GetOAuthCodeRequest, opts ...grpc.CallOption) (*GetOAuthCodeResponse, error)
-	GetTokenFromCode(ctx context.Context, in *GetTokenFromCodeRequest, opts ...grpc.CallOption) (*GetTokenFromCodeResponse, error)
+func (m *GetTokenFromCodeResponse) GetExpiresIn() int64 {
+	if m != nil {
+		return m.ExpiresIn
+	}
+	return 0
 }
 
-type userClient struct {
-	cc *grpc.ClientConn
+type GetClientCredentialsTokenRequest struct {
+	AppUid               string   `protobuf:"bytes,1,opt,name=appUid,proto3" json:"appUid,omitempty"`
+	AppSecret            string   `protobuf:"bytes,2,opt,name=appSecret,proto3" json:"appSecret,omitempty"`
+	Scopes               []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func NewUserClient(cc *grpc.ClientConn) UserClient {
-	return &userClient{cc}
+func (m *GetClientCredentialsTokenRequest) Reset()         { *m = GetClientCredentialsTokenRequest{} }
+func (m *GetClientCredentialsTokenRequest) String() string { return proto.CompactTextString(m) }
+func (*GetClientCredentialsTokenRequest) ProtoMessage()    {}
+func (*GetClientCredentialsTokenRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{22}
 }
-
-func (c *userClient) GetUserInfo(ctx context.Context, in *GetUserInfoRequest, opts ...grpc.CallOption) (*UserInfo, error) {
-	out := new(UserInfo)
-	err := c.cc.Invoke(ctx, "/user.user/GetUserInfo", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *GetClientCredentialsTokenRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetClientCredentialsTokenRequest.Unmarshal(m, b)
+}
+func (m *GetClientCredentialsTokenRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetClientCredentialsTokenRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetClientCredentialsTokenRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetClientCredentialsTokenRequest.Merge(dst, src)
+}
+func (m *GetClientCredentialsTokenRequest) XXX_Size() int {
+	return xxx_messageInfo_GetClientCredentialsTokenRequest.Size(m)
+}
+func (m *GetClientCredentialsTokenRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetClientCredentialsTokenRequest.DiscardUnknown(m)
 }
 
-func (c *userClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*UserInfo, error) {
-	out := new(UserInfo)
-	err := c.cc.Invoke(ctx, "/user.user/CreateUser", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_GetClientCredentialsTokenRequest proto.InternalMessageInfo
+
+func (m *GetClientCredentialsTokenRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
 	}
-	return out, nil
+	return ""
 }
 
-func (c *userClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
-	out := new(UpdateUserResponse)
-	err := c.cc.Invoke(ctx, "/user.user/UpdateUser", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *GetClientCredentialsTokenRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
 	}
-	return out, nil
+	return ""
 }
 
-func (c *userClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
-	out := new(DeleteUserResponse)
-	err := c.cc.Invoke(ctx, "/user.user/DeleteUser", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *GetClientCredentialsTokenRequest) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
 	}
-	return out, nil
+	return nil
 }
 
-func (c *userClient) GetAccessToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetAccessTokenResponse, error) {
-	out := new(GetAccessTokenResponse)
-	err := c.cc.Invoke(ctx, "/user.user/GetAccessToken", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type GetClientCredentialsTokenResponse struct {
+	AccessToken          string   `protobuf:"bytes,1,opt,name=accessToken,proto3" json:"accessToken,omitempty"`
+	ExpiresIn            int64    `protobuf:"varint,2,opt,name=expiresIn,proto3" json:"expiresIn,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *userClient) GetRefreshToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetRefreshTokenResponse, error) {
-	out := new(GetRefreshTokenResponse)
-	err := c.cc.Invoke(ctx, "/user.user/GetRefreshToken", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *GetClientCredentialsTokenResponse) Reset()         { *m = GetClientCredentialsTokenResponse{} }
+func (m *GetClientCredentialsTokenResponse) String() string { return proto.CompactTextString(m) }
+func (*GetClientCredentialsTokenResponse) ProtoMessage()    {}
+func (*GetClientCredentialsTokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{23}
+}
+func (m *GetClientCredentialsTokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetClientCredentialsTokenResponse.Unmarshal(m, b)
+}
+func (m *GetClientCredentialsTokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetClientCredentialsTokenResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetClientCredentialsTokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetClientCredentialsTokenResponse.Merge(dst, src)
+}
+func (m *GetClientCredentialsTokenResponse) XXX_Size() int {
+	return xxx_messageInfo_GetClientCredentialsTokenResponse.Size(m)
+}
+func (m *GetClientCredentialsTokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetClientCredentialsTokenResponse.DiscardUnknown(m)
 }
 
-func (c *userClient) RefreshAccessToken(ctx context.Context, in *RefreshAccessTokenRequest, opts ...grpc.CallOption) (*RefreshAccessTokenResponse, error) {
-	out := new(RefreshAccessTokenResponse)
-	err := c.cc.Invoke(ctx, "/user.user/RefreshAccessToken", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_GetClientCredentialsTokenResponse proto.InternalMessageInfo
+
+func (m *GetClientCredentialsTokenResponse) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
 	}
-	return out, nil
+	return ""
 }
 
-func (c *userClient) GetUserByAccessToken(ctx context.Context, in *GetUserByAccessTokenRequest, opts ...grpc.CallOption) (*GetUserByAccessTokenResponse, error) {
-	out := new(GetUserByAccessTokenResponse)
-	err := c.cc.Invoke(ctx, "/user.user/GetUserByAccessToken", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *GetClientCredentialsTokenResponse) GetExpiresIn() int64 {
+	if m != nil {
+		return m.ExpiresIn
 	}
-	return out, nil
+	return 0
 }
 
-func (c *userClient) CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error) {
-	out := new(CreateAppResponse)
-	err := c.cc.Invoke(ctx, "/user.user/CreateApp", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type GetPublicKeysRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *userClient) GetAppInfo(ctx context.Context, in *GetAppInfoRequest, opts ...grpc.CallOption) (*GetAppInfoResponse, error) {
-	out := new(GetAppInfoResponse)
-	err := c.cc.Invoke(ctx, "/user.user/GetAppInfo", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *GetPublicKeysRequest) Reset()         { *m = GetPublicKeysRequest{} }
+func (m *GetPublicKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPublicKeysRequest) ProtoMessage()    {}
+func (*GetPublicKeysRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{24}
+}
+func (m *GetPublicKeysRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPublicKeysRequest.Unmarshal(m, b)
+}
+func (m *GetPublicKeysRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPublicKeysRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetPublicKeysRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPublicKeysRequest.Merge(dst, src)
+}
+func (m *GetPublicKeysRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPublicKeysRequest.Size(m)
+}
+func (m *GetPublicKeysRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPublicKeysRequest.DiscardUnknown(m)
 }
 
-func (c *userClient) GetOAuthCode(ctx context.Context, in *GetOAuthCodeRequest, opts ...grpc.CallOption) (*GetOAuthCodeResponse, error) {
-	out := new(GetOAuthCodeResponse)
-	err := c.cc.Invoke(ctx, "/user.user/GetOAuthCode", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+var xxx_messageInfo_GetPublicKeysRequest proto.InternalMessageInfo
+
+type GetPublicKeysResponse struct {
+	Jwks                 string   `protobuf:"bytes,1,opt,name=jwks,proto3" json:"jwks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPublicKeysResponse) Reset()         { *m = GetPublicKeysResponse{} }
+func (m *GetPublicKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*GetPublicKeysResponse) ProtoMessage()    {}
+func (*GetPublicKeysResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{25}
+}
+func (m *GetPublicKeysResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPublicKeysResponse.Unmarshal(m, b)
+}
+func (m *GetPublicKeysResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPublicKeysResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetPublicKeysResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPublicKeysResponse.Merge(dst, src)
+}
+func (m *GetPublicKeysResponse) XXX_Size() int {
+	return xxx_messageInfo_GetPublicKeysResponse.Size(m)
+}
+func (m *GetPublicKeysResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPublicKeysResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPublicKeysResponse proto.InternalMessageInfo
+
+func (m *GetPublicKeysResponse) GetJwks() string {
+	if m != nil {
+		return m.Jwks
+	}
+	return ""
+}
+
+type RotateSigningKeyRequest struct {
+	UserToken            string   `protobuf:"bytes,1,opt,name=userToken,proto3" json:"userToken,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RotateSigningKeyRequest) Reset()         { *m = RotateSigningKeyRequest{} }
+func (m *RotateSigningKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*RotateSigningKeyRequest) ProtoMessage()    {}
+func (*RotateSigningKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{26}
+}
+func (m *RotateSigningKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RotateSigningKeyRequest.Unmarshal(m, b)
+}
+func (m *RotateSigningKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RotateSigningKeyRequest.Marshal(b, m, deterministic)
+}
+func (dst *RotateSigningKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RotateSigningKeyRequest.Merge(dst, src)
+}
+func (m *RotateSigningKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_RotateSigningKeyRequest.Size(m)
+}
+func (m *RotateSigningKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RotateSigningKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RotateSigningKeyRequest proto.InternalMessageInfo
+
+func (m *RotateSigningKeyRequest) GetUserToken() string {
+	if m != nil {
+		return m.UserToken
+	}
+	return ""
+}
+
+type RotateSigningKeyResponse struct {
+	Kid                  string   `protobuf:"bytes,1,opt,name=kid,proto3" json:"kid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RotateSigningKeyResponse) Reset()         { *m = RotateSigningKeyResponse{} }
+func (m *RotateSigningKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*RotateSigningKeyResponse) ProtoMessage()    {}
+func (*RotateSigningKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{27}
+}
+func (m *RotateSigningKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RotateSigningKeyResponse.Unmarshal(m, b)
+}
+func (m *RotateSigningKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RotateSigningKeyResponse.Marshal(b, m, deterministic)
+}
+func (dst *RotateSigningKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RotateSigningKeyResponse.Merge(dst, src)
+}
+func (m *RotateSigningKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_RotateSigningKeyResponse.Size(m)
+}
+func (m *RotateSigningKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RotateSigningKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RotateSigningKeyResponse proto.InternalMessageInfo
+
+func (m *RotateSigningKeyResponse) GetKid() string {
+	if m != nil {
+		return m.Kid
+	}
+	return ""
+}
+
+type RevokeTokenRequest struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TokenTypeHint        string   `protobuf:"bytes,2,opt,name=tokenTypeHint,proto3" json:"tokenTypeHint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeTokenRequest) Reset()         { *m = RevokeTokenRequest{} }
+func (m *RevokeTokenRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeTokenRequest) ProtoMessage()    {}
+func (*RevokeTokenRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{28}
+}
+func (m *RevokeTokenRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeTokenRequest.Unmarshal(m, b)
+}
+func (m *RevokeTokenRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeTokenRequest.Marshal(b, m, deterministic)
+}
+func (dst *RevokeTokenRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeTokenRequest.Merge(dst, src)
+}
+func (m *RevokeTokenRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeTokenRequest.Size(m)
+}
+func (m *RevokeTokenRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeTokenRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeTokenRequest proto.InternalMessageInfo
+
+func (m *RevokeTokenRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *RevokeTokenRequest) GetTokenTypeHint() string {
+	if m != nil {
+		return m.TokenTypeHint
+	}
+	return ""
+}
+
+type RevokeTokenResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeTokenResponse) Reset()         { *m = RevokeTokenResponse{} }
+func (m *RevokeTokenResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeTokenResponse) ProtoMessage()    {}
+func (*RevokeTokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{29}
+}
+func (m *RevokeTokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeTokenResponse.Unmarshal(m, b)
+}
+func (m *RevokeTokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeTokenResponse.Marshal(b, m, deterministic)
+}
+func (dst *RevokeTokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeTokenResponse.Merge(dst, src)
+}
+func (m *RevokeTokenResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeTokenResponse.Size(m)
+}
+func (m *RevokeTokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeTokenResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeTokenResponse proto.InternalMessageInfo
+
+type IntrospectTokenRequest struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TokenTypeHint        string   `protobuf:"bytes,2,opt,name=tokenTypeHint,proto3" json:"tokenTypeHint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IntrospectTokenRequest) Reset()         { *m = IntrospectTokenRequest{} }
+func (m *IntrospectTokenRequest) String() string { return proto.CompactTextString(m) }
+func (*IntrospectTokenRequest) ProtoMessage()    {}
+func (*IntrospectTokenRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{30}
+}
+func (m *IntrospectTokenRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntrospectTokenRequest.Unmarshal(m, b)
+}
+func (m *IntrospectTokenRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntrospectTokenRequest.Marshal(b, m, deterministic)
+}
+func (dst *IntrospectTokenRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntrospectTokenRequest.Merge(dst, src)
+}
+func (m *IntrospectTokenRequest) XXX_Size() int {
+	return xxx_messageInfo_IntrospectTokenRequest.Size(m)
+}
+func (m *IntrospectTokenRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntrospectTokenRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntrospectTokenRequest proto.InternalMessageInfo
+
+func (m *IntrospectTokenRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *IntrospectTokenRequest) GetTokenTypeHint() string {
+	if m != nil {
+		return m.TokenTypeHint
+	}
+	return ""
+}
+
+type IntrospectTokenResponse struct {
+	Active               bool     `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	Subject              string   `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	SubjectType          string   `protobuf:"bytes,3,opt,name=subjectType,proto3" json:"subjectType,omitempty"`
+	ClientApp            string   `protobuf:"bytes,4,opt,name=clientApp,proto3" json:"clientApp,omitempty"`
+	Scopes               []string `protobuf:"bytes,5,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	IssuedAt             int64    `protobuf:"varint,6,opt,name=issuedAt,proto3" json:"issuedAt,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,7,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+	TokenType            string   `protobuf:"bytes,8,opt,name=tokenType,proto3" json:"tokenType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IntrospectTokenResponse) Reset()         { *m = IntrospectTokenResponse{} }
+func (m *IntrospectTokenResponse) String() string { return proto.CompactTextString(m) }
+func (*IntrospectTokenResponse) ProtoMessage()    {}
+func (*IntrospectTokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{31}
+}
+func (m *IntrospectTokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IntrospectTokenResponse.Unmarshal(m, b)
+}
+func (m *IntrospectTokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IntrospectTokenResponse.Marshal(b, m, deterministic)
+}
+func (dst *IntrospectTokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IntrospectTokenResponse.Merge(dst, src)
+}
+func (m *IntrospectTokenResponse) XXX_Size() int {
+	return xxx_messageInfo_IntrospectTokenResponse.Size(m)
+}
+func (m *IntrospectTokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_IntrospectTokenResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IntrospectTokenResponse proto.InternalMessageInfo
+
+func (m *IntrospectTokenResponse) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *IntrospectTokenResponse) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *IntrospectTokenResponse) GetSubjectType() string {
+	if m != nil {
+		return m.SubjectType
+	}
+	return ""
+}
+
+func (m *IntrospectTokenResponse) GetClientApp() string {
+	if m != nil {
+		return m.ClientApp
+	}
+	return ""
+}
+
+func (m *IntrospectTokenResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *IntrospectTokenResponse) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *IntrospectTokenResponse) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *IntrospectTokenResponse) GetTokenType() string {
+	if m != nil {
+		return m.TokenType
+	}
+	return ""
+}
+
+type LogoutEverywhereRequest struct {
+	UserToken            string   `protobuf:"bytes,1,opt,name=userToken,proto3" json:"userToken,omitempty"`
+	Uid                  string   `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogoutEverywhereRequest) Reset()         { *m = LogoutEverywhereRequest{} }
+func (m *LogoutEverywhereRequest) String() string { return proto.CompactTextString(m) }
+func (*LogoutEverywhereRequest) ProtoMessage()    {}
+func (*LogoutEverywhereRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{32}
+}
+func (m *LogoutEverywhereRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogoutEverywhereRequest.Unmarshal(m, b)
+}
+func (m *LogoutEverywhereRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogoutEverywhereRequest.Marshal(b, m, deterministic)
+}
+func (dst *LogoutEverywhereRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogoutEverywhereRequest.Merge(dst, src)
+}
+func (m *LogoutEverywhereRequest) XXX_Size() int {
+	return xxx_messageInfo_LogoutEverywhereRequest.Size(m)
+}
+func (m *LogoutEverywhereRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogoutEverywhereRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogoutEverywhereRequest proto.InternalMessageInfo
+
+func (m *LogoutEverywhereRequest) GetUserToken() string {
+	if m != nil {
+		return m.UserToken
+	}
+	return ""
+}
+
+func (m *LogoutEverywhereRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+type LogoutEverywhereResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogoutEverywhereResponse) Reset()         { *m = LogoutEverywhereResponse{} }
+func (m *LogoutEverywhereResponse) String() string { return proto.CompactTextString(m) }
+func (*LogoutEverywhereResponse) ProtoMessage()    {}
+func (*LogoutEverywhereResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{33}
+}
+func (m *LogoutEverywhereResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogoutEverywhereResponse.Unmarshal(m, b)
+}
+func (m *LogoutEverywhereResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogoutEverywhereResponse.Marshal(b, m, deterministic)
+}
+func (dst *LogoutEverywhereResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogoutEverywhereResponse.Merge(dst, src)
+}
+func (m *LogoutEverywhereResponse) XXX_Size() int {
+	return xxx_messageInfo_LogoutEverywhereResponse.Size(m)
+}
+func (m *LogoutEverywhereResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogoutEverywhereResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogoutEverywhereResponse proto.InternalMessageInfo
+
+type GetUserClaimsRequest struct {
+	UserToken            string   `protobuf:"bytes,1,opt,name=userToken,proto3" json:"userToken,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUserClaimsRequest) Reset()         { *m = GetUserClaimsRequest{} }
+func (m *GetUserClaimsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUserClaimsRequest) ProtoMessage()    {}
+func (*GetUserClaimsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{34}
+}
+func (m *GetUserClaimsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUserClaimsRequest.Unmarshal(m, b)
+}
+func (m *GetUserClaimsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUserClaimsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetUserClaimsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUserClaimsRequest.Merge(dst, src)
+}
+func (m *GetUserClaimsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetUserClaimsRequest.Size(m)
+}
+func (m *GetUserClaimsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUserClaimsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUserClaimsRequest proto.InternalMessageInfo
+
+func (m *GetUserClaimsRequest) GetUserToken() string {
+	if m != nil {
+		return m.UserToken
+	}
+	return ""
+}
+
+type GetUserClaimsResponse struct {
+	Sub                  string   `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	PreferredUsername    string   `protobuf:"bytes,2,opt,name=preferredUsername,proto3" json:"preferredUsername,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUserClaimsResponse) Reset()         { *m = GetUserClaimsResponse{} }
+func (m *GetUserClaimsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetUserClaimsResponse) ProtoMessage()    {}
+func (*GetUserClaimsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_c2e94eab3735f647, []int{35}
+}
+func (m *GetUserClaimsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUserClaimsResponse.Unmarshal(m, b)
+}
+func (m *GetUserClaimsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUserClaimsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetUserClaimsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUserClaimsResponse.Merge(dst, src)
+}
+func (m *GetUserClaimsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetUserClaimsResponse.Size(m)
+}
+func (m *GetUserClaimsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUserClaimsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUserClaimsResponse proto.InternalMessageInfo
+
+func (m *GetUserClaimsResponse) GetSub() string {
+	if m != nil {
+		return m.Sub
+	}
+	return ""
+}
+
+func (m *GetUserClaimsResponse) GetPreferredUsername() string {
+	if m != nil {
+		return m.PreferredUsername
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*GetUserInfoRequest)(nil), "user.GetUserInfoRequest")
+	proto.RegisterType((*UserInfo)(nil), "user.UserInfo")
+	proto.RegisterType((*CreateUserRequest)(nil), "user.CreateUserRequest")
+	proto.RegisterType((*UpdateUserRequest)(nil), "user.UpdateUserRequest")
+	proto.RegisterType((*UpdateUserResponse)(nil), "user.UpdateUserResponse")
+	proto.RegisterType((*DeleteUserRequest)(nil), "user.DeleteUserRequest")
+	proto.RegisterType((*DeleteUserResponse)(nil), "user.DeleteUserResponse")
+	proto.RegisterType((*GetTokenRequest)(nil), "user.GetTokenRequest")
+	proto.RegisterType((*GetAccessTokenResponse)(nil), "user.GetAccessTokenResponse")
+	proto.RegisterType((*GetUserByAccessTokenRequest)(nil), "user.GetUserByAccessTokenRequest")
+	proto.RegisterType((*GetUserByAccessTokenResponse)(nil), "user.GetUserByAccessTokenResponse")
+	proto.RegisterType((*GetRefreshTokenResponse)(nil), "user.GetRefreshTokenResponse")
+	proto.RegisterType((*RefreshAccessTokenRequest)(nil), "user.RefreshAccessTokenRequest")
+	proto.RegisterType((*RefreshAccessTokenResponse)(nil), "user.RefreshAccessTokenResponse")
+	proto.RegisterType((*CreateAppRequest)(nil), "user.CreateAppRequest")
+	proto.RegisterType((*CreateAppResponse)(nil), "user.CreateAppResponse")
+	proto.RegisterType((*GetAppInfoRequest)(nil), "user.GetAppInfoRequest")
+	proto.RegisterType((*GetAppInfoResponse)(nil), "user.GetAppInfoResponse")
+	proto.RegisterType((*GetOAuthCodeRequest)(nil), "user.GetOAuthCodeRequest")
+	proto.RegisterType((*GetOAuthCodeResponse)(nil), "user.GetOAuthCodeResponse")
+	proto.RegisterType((*GetTokenFromCodeRequest)(nil), "user.GetTokenFromCodeRequest")
+	proto.RegisterType((*GetTokenFromCodeResponse)(nil), "user.GetTokenFromCodeResponse")
+	proto.RegisterType((*GetClientCredentialsTokenRequest)(nil), "user.GetClientCredentialsTokenRequest")
+	proto.RegisterType((*GetClientCredentialsTokenResponse)(nil), "user.GetClientCredentialsTokenResponse")
+	proto.RegisterType((*GetPublicKeysRequest)(nil), "user.GetPublicKeysRequest")
+	proto.RegisterType((*GetPublicKeysResponse)(nil), "user.GetPublicKeysResponse")
+	proto.RegisterType((*RotateSigningKeyRequest)(nil), "user.RotateSigningKeyRequest")
+	proto.RegisterType((*RotateSigningKeyResponse)(nil), "user.RotateSigningKeyResponse")
+	proto.RegisterType((*RevokeTokenRequest)(nil), "user.RevokeTokenRequest")
+	proto.RegisterType((*RevokeTokenResponse)(nil), "user.RevokeTokenResponse")
+	proto.RegisterType((*IntrospectTokenRequest)(nil), "user.IntrospectTokenRequest")
+	proto.RegisterType((*IntrospectTokenResponse)(nil), "user.IntrospectTokenResponse")
+	proto.RegisterType((*LogoutEverywhereRequest)(nil), "user.LogoutEverywhereRequest")
+	proto.RegisterType((*LogoutEverywhereResponse)(nil), "user.LogoutEverywhereResponse")
+	proto.RegisterType((*GetUserClaimsRequest)(nil), "user.GetUserClaimsRequest")
+	proto.RegisterType((*GetUserClaimsResponse)(nil), "user.GetUserClaimsResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// UserClient is the client API for User service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type UserClient interface {
+	GetUserInfo(ctx context.Context, in *GetUserInfoRequest, opts ...grpc.CallOption) (*UserInfo, error)
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*UserInfo, error)
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
+	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
+	GetAccessToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetAccessTokenResponse, error)
+	GetRefreshToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetRefreshTokenResponse, error)
+	RefreshAccessToken(ctx context.Context, in *RefreshAccessTokenRequest, opts ...grpc.CallOption) (*RefreshAccessTokenResponse, error)
+	GetUserByAccessToken(ctx context.Context, in *GetUserByAccessTokenRequest, opts ...grpc.CallOption) (*GetUserByAccessTokenResponse, error)
+	CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error)
+	GetAppInfo(ctx context.Context, in *GetAppInfoRequest, opts ...grpc.CallOption) (*GetAppInfoResponse, error)
+	GetOAuthCode(ctx context.Context, in *GetOAuthCodeRequest, opts ...grpc.CallOption) (*GetOAuthCodeResponse, error)
+	GetTokenFromCode(ctx context.Context, in *GetTokenFromCodeRequest, opts ...grpc.CallOption) (*GetTokenFromCodeResponse, error)
+	GetClientCredentialsToken(ctx context.Context, in *GetClientCredentialsTokenRequest, opts ...grpc.CallOption) (*GetClientCredentialsTokenResponse, error)
+	GetPublicKeys(ctx context.Context, in *GetPublicKeysRequest, opts ...grpc.CallOption) (*GetPublicKeysResponse, error)
+	RotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest, opts ...grpc.CallOption) (*RotateSigningKeyResponse, error)
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error)
+	LogoutEverywhere(ctx context.Context, in *LogoutEverywhereRequest, opts ...grpc.CallOption) (*LogoutEverywhereResponse, error)
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error)
+	GetUserClaims(ctx context.Context, in *GetUserClaimsRequest, opts ...grpc.CallOption) (*GetUserClaimsResponse, error)
+}
+
+type userClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewUserClient(cc *grpc.ClientConn) UserClient {
+	return &userClient{cc}
+}
+
+func (c *userClient) GetUserInfo(ctx context.Context, in *GetUserInfoRequest, opts ...grpc.CallOption) (*UserInfo, error) {
+	out := new(UserInfo)
+	err := c.cc.Invoke(ctx, "/user.user/GetUserInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*UserInfo, error) {
+	out := new(UserInfo)
+	err := c.cc.Invoke(ctx, "/user.user/CreateUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
+	out := new(UpdateUserResponse)
+	err := c.cc.Invoke(ctx, "/user.user/UpdateUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
+	out := new(DeleteUserResponse)
+	err := c.cc.Invoke(ctx, "/user.user/DeleteUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetAccessToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetAccessTokenResponse, error) {
+	out := new(GetAccessTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetAccessToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetRefreshToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*GetRefreshTokenResponse, error) {
+	out := new(GetRefreshTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetRefreshToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RefreshAccessToken(ctx context.Context, in *RefreshAccessTokenRequest, opts ...grpc.CallOption) (*RefreshAccessTokenResponse, error) {
+	out := new(RefreshAccessTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/RefreshAccessToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetUserByAccessToken(ctx context.Context, in *GetUserByAccessTokenRequest, opts ...grpc.CallOption) (*GetUserByAccessTokenResponse, error) {
+	out := new(GetUserByAccessTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetUserByAccessToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error) {
+	out := new(CreateAppResponse)
+	err := c.cc.Invoke(ctx, "/user.user/CreateApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetAppInfo(ctx context.Context, in *GetAppInfoRequest, opts ...grpc.CallOption) (*GetAppInfoResponse, error) {
+	out := new(GetAppInfoResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetAppInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetOAuthCode(ctx context.Context, in *GetOAuthCodeRequest, opts ...grpc.CallOption) (*GetOAuthCodeResponse, error) {
+	out := new(GetOAuthCodeResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetOAuthCode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (c *userClient) GetTokenFromCode(ctx context.Context, in *GetTokenFromCodeRequest, opts ...grpc.CallOption) (*GetTokenFromCodeResponse, error) {
@@ -1154,6 +1963,69 @@ func (c *userClient) GetTokenFromCode(ctx context.Context, in *GetTokenFromCodeR
 	return out, nil
 }
 
+func (c *userClient) GetClientCredentialsToken(ctx context.Context, in *GetClientCredentialsTokenRequest, opts ...grpc.CallOption) (*GetClientCredentialsTokenResponse, error) {
+	out := new(GetClientCredentialsTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetClientCredentialsToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetPublicKeys(ctx context.Context, in *GetPublicKeysRequest, opts ...grpc.CallOption) (*GetPublicKeysResponse, error) {
+	out := new(GetPublicKeysResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetPublicKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest, opts ...grpc.CallOption) (*RotateSigningKeyResponse, error) {
+	out := new(RotateSigningKeyResponse)
+	err := c.cc.Invoke(ctx, "/user.user/RotateSigningKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error) {
+	out := new(RevokeTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/RevokeToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) LogoutEverywhere(ctx context.Context, in *LogoutEverywhereRequest, opts ...grpc.CallOption) (*LogoutEverywhereResponse, error) {
+	out := new(LogoutEverywhereResponse)
+	err := c.cc.Invoke(ctx, "/user.user/LogoutEverywhere", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error) {
+	out := new(IntrospectTokenResponse)
+	err := c.cc.Invoke(ctx, "/user.user/IntrospectToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetUserClaims(ctx context.Context, in *GetUserClaimsRequest, opts ...grpc.CallOption) (*GetUserClaimsResponse, error) {
+	out := new(GetUserClaimsResponse)
+	err := c.cc.Invoke(ctx, "/user.user/GetUserClaims", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServer is the server API for User service.
 type UserServer interface {
 	GetUserInfo(context.Context, *GetUserInfoRequest) (*UserInfo, error)
@@ -1168,6 +2040,13 @@ type UserServer interface {
 	GetAppInfo(context.Context, *GetAppInfoRequest) (*GetAppInfoResponse, error)
 	GetOAuthCode(context.Context, *GetOAuthCodeRequest) (*GetOAuthCodeResponse, error)
 	GetTokenFromCode(context.Context, *GetTokenFromCodeRequest) (*GetTokenFromCodeResponse, error)
+	GetClientCredentialsToken(context.Context, *GetClientCredentialsTokenRequest) (*GetClientCredentialsTokenResponse, error)
+	GetPublicKeys(context.Context, *GetPublicKeysRequest) (*GetPublicKeysResponse, error)
+	RotateSigningKey(context.Context, *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error)
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	LogoutEverywhere(context.Context, *LogoutEverywhereRequest) (*LogoutEverywhereResponse, error)
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error)
+	GetUserClaims(context.Context, *GetUserClaimsRequest) (*GetUserClaimsResponse, error)
 }
 
 func RegisterUserServer(s *grpc.Server, srv UserServer) {
@@ -1390,6 +2269,132 @@ func _User_GetTokenFromCode_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _User_GetClientCredentialsToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClientCredentialsTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).GetClientCredentialsToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/GetClientCredentialsToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).GetClientCredentialsToken(ctx, req.(*GetClientCredentialsTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_GetPublicKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).GetPublicKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/GetPublicKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).GetPublicKeys(ctx, req.(*GetPublicKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RotateSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateSigningKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RotateSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/RotateSigningKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RotateSigningKey(ctx, req.(*RotateSigningKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/RevokeToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_LogoutEverywhere_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutEverywhereRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).LogoutEverywhere(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/LogoutEverywhere",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).LogoutEverywhere(ctx, req.(*LogoutEverywhereRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/IntrospectToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_GetUserClaims_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserClaimsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).GetUserClaims(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.user/GetUserClaims",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).GetUserClaims(ctx, req.(*GetUserClaimsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _User_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "user.user",
 	HandlerType: (*UserServer)(nil),
@@ -1442,56 +2447,125 @@ var _User_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetTokenFromCode",
 			Handler:    _User_GetTokenFromCode_Handler,
 		},
+		{
+			MethodName: "GetClientCredentialsToken",
+			Handler:    _User_GetClientCredentialsToken_Handler,
+		},
+		{
+			MethodName: "GetPublicKeys",
+			Handler:    _User_GetPublicKeys_Handler,
+		},
+		{
+			MethodName: "RotateSigningKey",
+			Handler:    _User_RotateSigningKey_Handler,
+		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _User_RevokeToken_Handler,
+		},
+		{
+			MethodName: "LogoutEverywhere",
+			Handler:    _User_LogoutEverywhere_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _User_IntrospectToken_Handler,
+		},
+		{
+			MethodName: "GetUserClaims",
+			Handler:    _User_GetUserClaims_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/user/proto/user.proto",
 }
 
-func init() { proto.RegisterFile("pkg/user/proto/user.proto", fileDescriptor_user_d91e02009efc5113) }
-
-var fileDescriptor_user_d91e02009efc5113 = []byte{
-	// 686 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdf, 0x6f, 0xd3, 0x30,
-	0x10, 0x56, 0xb3, 0x51, 0xda, 0xdb, 0x34, 0x56, 0x33, 0xba, 0xcc, 0x74, 0x50, 0x8c, 0x84, 0x26,
-	0x1e, 0x36, 0x04, 0x42, 0x3c, 0x6c, 0x62, 0x94, 0x01, 0xd5, 0x9e, 0x10, 0x85, 0x89, 0x27, 0x04,
-	0x59, 0x73, 0x63, 0xd5, 0x58, 0x62, 0xe2, 0x54, 0x13, 0x7f, 0x2a, 0xff, 0x0d, 0x8a, 0x63, 0x3b,
-	0x4e, 0x9c, 0x4c, 0x15, 0xf0, 0x96, 0xfb, 0xf5, 0x7d, 0x67, 0x9f, 0xef, 0x53, 0x60, 0x8b, 0x5f,
-	0x7c, 0xdf, 0x9b, 0x0b, 0x4c, 0xf6, 0x78, 0x12, 0xa7, 0xb1, 0xfc, 0xdc, 0x95, 0x9f, 0x64, 0x39,
-	0xfb, 0x66, 0x8f, 0x80, 0x8c, 0x31, 0x3d, 0x11, 0x98, 0x1c, 0x47, 0x67, 0xf1, 0x04, 0x7f, 0xce,
-	0x51, 0xa4, 0x64, 0x1d, 0x96, 0xe6, 0xb3, 0xd0, 0x6f, 0x0d, 0x5b, 0x3b, 0xdd, 0x49, 0xf6, 0xc9,
-	0x26, 0xd0, 0xd1, 0x49, 0x6e, 0x94, 0x50, 0xe8, 0x64, 0x68, 0x51, 0x70, 0x89, 0xbe, 0x27, 0xdd,
-	0xc6, 0x26, 0x3e, 0xdc, 0x9c, 0x89, 0x51, 0x78, 0x39, 0x8b, 0xfc, 0xa5, 0x61, 0x6b, 0xa7, 0x33,
-	0xd1, 0x26, 0x0b, 0xa0, 0x77, 0x94, 0x60, 0x90, 0x62, 0x86, 0xac, 0xa9, 0x37, 0xe0, 0x46, 0x1a,
-	0x5f, 0x60, 0xa4, 0xe0, 0x73, 0xe3, 0x5a, 0x02, 0x0a, 0x1d, 0x1e, 0x08, 0x71, 0x15, 0x27, 0xa1,
-	0x64, 0xe8, 0x4e, 0x8c, 0xcd, 0xbe, 0x42, 0xef, 0x84, 0x87, 0x15, 0x8a, 0x01, 0x74, 0xb3, 0xe2,
-	0x4f, 0x16, 0x4d, 0xe1, 0xd0, 0xa7, 0xf3, 0x4a, 0xa7, 0x6b, 0x24, 0xd8, 0x00, 0x62, 0x13, 0x08,
-	0x1e, 0x47, 0x02, 0xd9, 0x11, 0xf4, 0xde, 0xe0, 0x0f, 0xfc, 0x27, 0xda, 0x0c, 0xda, 0x06, 0x51,
-	0xd0, 0xc7, 0x70, 0x6b, 0x8c, 0xa9, 0xac, 0xd1, 0xc0, 0xf6, 0xe5, 0xb4, 0xae, 0xb9, 0x1c, 0xaf,
-	0xd2, 0xfb, 0x2b, 0xe8, 0x8f, 0x31, 0x1d, 0x4d, 0xa7, 0x28, 0x84, 0x02, 0xcc, 0x49, 0x1a, 0x86,
-	0xe0, 0xb6, 0xb8, 0x0f, 0x77, 0xd5, 0xeb, 0x79, 0xfd, 0xab, 0x84, 0xb3, 0xc0, 0x89, 0xd9, 0x13,
-	0x18, 0xd4, 0x17, 0xab, 0x26, 0xdc, 0x47, 0xb8, 0x07, 0x9b, 0x63, 0x4c, 0x27, 0x78, 0x96, 0xa0,
-	0x38, 0x5f, 0xa0, 0x63, 0x76, 0x08, 0x5b, 0x2a, 0xbb, 0xa6, 0x3b, 0x06, 0xab, 0x89, 0x05, 0xa5,
-	0x2a, 0x4b, 0x3e, 0x76, 0x0a, 0xb4, 0x0e, 0x40, 0x91, 0x0e, 0x61, 0x25, 0x28, 0xdc, 0x0a, 0xc0,
-	0x76, 0x39, 0x1c, 0x5e, 0x0d, 0xc7, 0x01, 0xac, 0xe7, 0x6b, 0x30, 0xe2, 0xdc, 0xda, 0x82, 0xf8,
-	0x2a, 0xc2, 0x44, 0x1f, 0x47, 0x1a, 0x84, 0xc0, 0xb2, 0xb5, 0x01, 0xf2, 0x9b, 0xed, 0xeb, 0x25,
-	0x92, 0xd5, 0xaa, 0xb1, 0x35, 0xf0, 0xcc, 0xcd, 0x79, 0xb3, 0x90, 0xf4, 0xa1, 0x2d, 0x70, 0x9a,
-	0x60, 0xaa, 0x4a, 0x95, 0xc5, 0x1e, 0x42, 0x2f, 0x7b, 0x01, 0x9c, 0xdb, 0xcb, 0x5f, 0x29, 0x66,
-	0x2f, 0xa5, 0x44, 0x98, 0xa4, 0xe2, 0xc2, 0x17, 0xec, 0x10, 0xe1, 0xf6, 0x18, 0xd3, 0xf7, 0xa3,
-	0x79, 0x7a, 0x7e, 0x14, 0x87, 0xa8, 0x69, 0xfa, 0xd0, 0x0e, 0x38, 0x3f, 0x31, 0x54, 0xca, 0xfa,
-	0xeb, 0x55, 0x7f, 0x0c, 0x1b, 0x65, 0x1a, 0xd5, 0x28, 0x81, 0xe5, 0x69, 0x1c, 0xea, 0xcd, 0x90,
-	0xdf, 0x6c, 0x2a, 0x1f, 0x92, 0xbc, 0xfe, 0x77, 0x49, 0x7c, 0x69, 0xb7, 0x55, 0x93, 0x6e, 0xb5,
-	0xea, 0x95, 0x5a, 0x1d, 0x40, 0x37, 0xe0, 0xfc, 0x63, 0x7e, 0xb3, 0x79, 0x3f, 0x85, 0x83, 0x7d,
-	0x03, 0xdf, 0x25, 0xf9, 0x9f, 0x2f, 0xe7, 0xe9, 0xef, 0x36, 0x48, 0x15, 0x27, 0x2f, 0x60, 0xc5,
-	0x52, 0x71, 0xe2, 0xef, 0x4a, 0x9d, 0x77, 0x85, 0x9d, 0xae, 0xe5, 0x11, 0x93, 0xf9, 0x1c, 0xa0,
-	0x90, 0x60, 0xb2, 0x99, 0x47, 0x1d, 0x51, 0x76, 0xca, 0x0e, 0x01, 0x0a, 0xd5, 0xd3, 0x65, 0x8e,
-	0xd0, 0x52, 0xdf, 0x0d, 0xa8, 0xf3, 0x1f, 0x02, 0x14, 0xda, 0xa6, 0x01, 0x1c, 0xc9, 0xd4, 0x00,
-	0xae, 0x0c, 0x92, 0xb7, 0xb0, 0x56, 0xd6, 0x2e, 0x72, 0xc7, 0x1c, 0xda, 0xde, 0x72, 0x3a, 0x30,
-	0xee, 0xba, 0x0d, 0x1e, 0x4b, 0x35, 0xb5, 0x15, 0xa5, 0x09, 0x67, 0xdb, 0xb8, 0x6b, 0xf5, 0xe7,
-	0x33, 0x10, 0x57, 0x28, 0xc8, 0xfd, 0xbc, 0xa8, 0x51, 0x83, 0xe8, 0xb0, 0x39, 0x41, 0x01, 0x7f,
-	0x91, 0xcf, 0xda, 0x51, 0x49, 0xf2, 0xa0, 0x34, 0xe3, 0x3a, 0xf9, 0xa5, 0xec, 0xba, 0x14, 0x05,
-	0x7f, 0x00, 0x5d, 0x23, 0x1f, 0xa4, 0x6f, 0xcf, 0xbf, 0x50, 0x23, 0xba, 0xe9, 0xf8, 0x8b, 0x31,
-	0x16, 0xd2, 0xa0, 0xc7, 0xe8, 0x28, 0x0a, 0xf5, 0xdd, 0x80, 0x19, 0xe3, 0xaa, 0xbd, 0xb4, 0x64,
-	0xcb, 0x64, 0x56, 0xf5, 0x82, 0xd2, 0xba, 0x90, 0x82, 0xf9, 0x00, 0xeb, 0xd5, 0x55, 0x23, 0xdb,
-	0xe5, 0x39, 0x56, 0xf6, 0x9c, 0xde, 0x6b, 0x0a, 0xe7, 0x90, 0xa7, 0x6d, 0xf9, 0x97, 0xf4, 0xec,
-	0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xe7, 0xb3, 0xde, 0x42, 0x42, 0x09, 0x00, 0x00,
+func init() { proto.RegisterFile("pkg/user/proto/user.proto", fileDescriptor_user_c2e94eab3735f647) }
+
+var fileDescriptor_user_c2e94eab3735f647 = []byte{
+	// 1340 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xdf, 0x73, 0xd4, 0xb6,
+	0x13, 0x9f, 0xbb, 0xfc, 0xe0, 0x6e, 0xc3, 0x8f, 0x44, 0x5c, 0x12, 0xc7, 0x04, 0xbe, 0x87, 0xbe,
+	0x0c, 0xcd, 0x50, 0x06, 0x3a, 0xb4, 0x1d, 0x1e, 0xda, 0x19, 0xe6, 0xb8, 0xd2, 0x23, 0x03, 0x6d,
+	0xa9, 0x21, 0xf0, 0xd4, 0x76, 0x2e, 0xf6, 0x26, 0x31, 0x77, 0xb1, 0x5d, 0x4b, 0x47, 0xc8, 0x5b,
+	0xff, 0x81, 0x3e, 0x76, 0xa6, 0x7f, 0x40, 0x5f, 0xfa, 0x37, 0xf6, 0xa5, 0x63, 0x59, 0xb6, 0x24,
+	0xcb, 0x4e, 0x6e, 0x28, 0x6f, 0xd6, 0xae, 0xf4, 0x59, 0xed, 0x67, 0x57, 0xda, 0x95, 0x61, 0x2b,
+	0x99, 0x1c, 0xde, 0x9f, 0x31, 0x4c, 0xef, 0x27, 0x69, 0xcc, 0x63, 0xf1, 0x79, 0x4f, 0x7c, 0x92,
+	0xc5, 0xec, 0x9b, 0xde, 0x06, 0x32, 0x42, 0xbe, 0xc7, 0x30, 0xdd, 0x8d, 0x0e, 0x62, 0x0f, 0x7f,
+	0x9d, 0x21, 0xe3, 0x64, 0x15, 0x16, 0x66, 0x61, 0xe0, 0xb4, 0xfa, 0xad, 0x9d, 0xae, 0x97, 0x7d,
+	0x52, 0x0f, 0x3a, 0xc5, 0x24, 0x5b, 0x4b, 0x5c, 0xe8, 0x64, 0x68, 0xd1, 0xf8, 0x18, 0x9d, 0xb6,
+	0x10, 0x97, 0x63, 0xe2, 0xc0, 0x85, 0x90, 0x0d, 0x82, 0xe3, 0x30, 0x72, 0x16, 0xfa, 0xad, 0x9d,
+	0x8e, 0x57, 0x0c, 0xe9, 0x18, 0xd6, 0x86, 0x29, 0x8e, 0x39, 0x66, 0xc8, 0x85, 0xe9, 0x1e, 0x2c,
+	0xf1, 0x78, 0x82, 0x91, 0x84, 0xcf, 0x07, 0x67, 0x1a, 0x70, 0xa1, 0x93, 0x8c, 0x19, 0x3b, 0x89,
+	0xd3, 0x40, 0x58, 0xe8, 0x7a, 0xe5, 0x98, 0xfe, 0x02, 0x6b, 0x7b, 0x49, 0x50, 0x31, 0xb1, 0x0d,
+	0xdd, 0x6c, 0xf1, 0x2b, 0xcd, 0x8c, 0x12, 0x14, 0xde, 0xb5, 0x0d, 0xef, 0x1a, 0x0d, 0xf4, 0x80,
+	0xe8, 0x06, 0x58, 0x12, 0x47, 0x0c, 0xe9, 0x10, 0xd6, 0xbe, 0xc1, 0x29, 0xfe, 0x27, 0xb3, 0x19,
+	0xb4, 0x0e, 0x22, 0xa1, 0x77, 0xe1, 0xca, 0x08, 0xb9, 0x58, 0x53, 0x00, 0xeb, 0xe4, 0xb4, 0xce,
+	0x20, 0xa7, 0x5d, 0xd9, 0xfb, 0xcf, 0xb0, 0x31, 0x42, 0x3e, 0xf0, 0x7d, 0x64, 0x4c, 0x02, 0xe6,
+	0x46, 0x1a, 0x82, 0x60, 0x33, 0xb3, 0x0d, 0x5d, 0x7c, 0x9f, 0x84, 0x29, 0xb2, 0xdd, 0x3c, 0xba,
+	0x0b, 0x9e, 0x12, 0xd0, 0x43, 0xb8, 0x26, 0x73, 0xeb, 0xf1, 0xa9, 0x61, 0x65, 0x1e, 0x3e, 0xee,
+	0xc0, 0x2a, 0xbe, 0xe7, 0x18, 0x05, 0x4f, 0x32, 0xbc, 0x31, 0x0f, 0xe3, 0x48, 0x58, 0xee, 0x78,
+	0x96, 0x9c, 0xbe, 0x85, 0xed, 0x7a, 0x43, 0xd2, 0x1d, 0x3b, 0x61, 0x37, 0x60, 0x99, 0xf9, 0x71,
+	0x82, 0xcc, 0x69, 0xf7, 0x17, 0x76, 0xba, 0x9e, 0x1c, 0x91, 0x3e, 0xac, 0xb0, 0xd9, 0xfe, 0x5b,
+	0xf4, 0xf9, 0xab, 0xd3, 0x04, 0x65, 0xb4, 0x75, 0x11, 0xbd, 0x0f, 0x9b, 0x23, 0xe4, 0x1e, 0x1e,
+	0xa4, 0xc8, 0x8e, 0xe6, 0x60, 0x8d, 0x3e, 0x82, 0x2d, 0x39, 0xbb, 0x86, 0x03, 0x0a, 0x17, 0x53,
+	0x0d, 0x4a, 0xae, 0x34, 0x64, 0xf4, 0xb7, 0x16, 0xb8, 0x75, 0x08, 0xd2, 0x6a, 0x1f, 0x56, 0xc6,
+	0x4a, 0x2c, 0x11, 0x74, 0x91, 0x65, 0xa4, 0x6d, 0x1b, 0x39, 0x27, 0x92, 0x7f, 0xb5, 0x61, 0x35,
+	0x3f, 0xaa, 0x83, 0x24, 0xd1, 0x4e, 0x6a, 0x7c, 0x12, 0x61, 0x5a, 0xb8, 0x2b, 0x06, 0x84, 0xc0,
+	0xa2, 0x76, 0x4a, 0xc5, 0x77, 0xbe, 0x81, 0x20, 0x4c, 0xd1, 0xe7, 0x7b, 0x69, 0xc8, 0x9c, 0x05,
+	0xc1, 0xb9, 0x21, 0xcb, 0x12, 0x35, 0x64, 0x2f, 0x66, 0xfb, 0xd3, 0xd0, 0x77, 0x16, 0x45, 0x9c,
+	0xcb, 0xb1, 0x16, 0xad, 0x25, 0x23, 0x5a, 0x9f, 0xc1, 0x55, 0xcd, 0xcf, 0xe7, 0xe1, 0x01, 0xf2,
+	0xf0, 0x18, 0x9d, 0x65, 0xb1, 0xfd, 0x3a, 0x15, 0x79, 0x00, 0x3d, 0xdd, 0xed, 0x72, 0xc9, 0x05,
+	0xb1, 0xa4, 0x56, 0x47, 0x76, 0xe0, 0x0a, 0x43, 0xc6, 0xc2, 0x58, 0x4d, 0xef, 0x88, 0xe9, 0x55,
+	0x31, 0xfd, 0x0a, 0xd6, 0x34, 0x96, 0x64, 0x7c, 0x2e, 0x43, 0xbb, 0xcc, 0xbd, 0xb6, 0x4c, 0x3d,
+	0xf4, 0x53, 0xe4, 0x92, 0x22, 0x39, 0xa2, 0xff, 0x87, 0xb5, 0xec, 0x34, 0x26, 0x89, 0x7e, 0x11,
+	0x57, 0x16, 0xd3, 0xd7, 0x40, 0xf4, 0x49, 0x2a, 0xf1, 0xe6, 0x8c, 0x84, 0x62, 0x72, 0x41, 0x67,
+	0x92, 0xfe, 0xde, 0x86, 0xab, 0x23, 0xe4, 0x3f, 0x0c, 0x66, 0xfc, 0x68, 0x18, 0x07, 0x58, 0xd8,
+	0xdf, 0x80, 0xe5, 0x71, 0x92, 0xec, 0x95, 0x7b, 0x90, 0xa3, 0x0f, 0xbd, 0x8f, 0xb3, 0x64, 0xd5,
+	0xa2, 0x2e, 0x02, 0xdd, 0xf5, 0x74, 0x11, 0xb9, 0x05, 0x97, 0xfc, 0x38, 0xc0, 0xe1, 0xd1, 0x78,
+	0x3a, 0xc5, 0xe8, 0x10, 0x9d, 0x25, 0x31, 0xc7, 0x14, 0x66, 0x91, 0x37, 0x04, 0xdf, 0x21, 0x3f,
+	0x8a, 0x03, 0x11, 0xf9, 0xae, 0x57, 0xa7, 0xd2, 0x3c, 0xbf, 0x60, 0xe4, 0x50, 0x0f, 0x96, 0xa2,
+	0x38, 0xf2, 0xf3, 0x98, 0x76, 0xbd, 0x7c, 0x40, 0xef, 0x40, 0xcf, 0xa4, 0x43, 0x32, 0x4d, 0x60,
+	0x31, 0x03, 0x97, 0x6c, 0x88, 0x6f, 0xfa, 0x77, 0x4b, 0x5c, 0x09, 0x22, 0x69, 0xbe, 0x4d, 0xe3,
+	0x63, 0x9d, 0xbf, 0x9a, 0xf9, 0x1a, 0xa7, 0x6d, 0x83, 0xd3, 0x6d, 0xe8, 0x8e, 0x93, 0xe4, 0x65,
+	0x9e, 0x1b, 0x39, 0x71, 0x4a, 0x30, 0x07, 0x73, 0x14, 0x2e, 0x66, 0xf8, 0xaf, 0x31, 0x0d, 0x0f,
+	0x42, 0x4c, 0x25, 0x71, 0x86, 0x8c, 0xfe, 0xd9, 0x02, 0xc7, 0xde, 0xeb, 0x47, 0xbd, 0x49, 0xb2,
+	0x7a, 0x1f, 0xe4, 0xea, 0xdc, 0x89, 0x62, 0x68, 0xde, 0x31, 0x8b, 0xd5, 0x3b, 0x26, 0x81, 0xfe,
+	0x08, 0xf9, 0x70, 0x1a, 0x62, 0xc4, 0x87, 0x29, 0x06, 0x18, 0xf1, 0x70, 0x3c, 0x35, 0xaf, 0xcb,
+	0xa6, 0x74, 0x34, 0xa8, 0x6b, 0x57, 0xa9, 0x6b, 0x4a, 0x7a, 0x1f, 0x6e, 0x9e, 0x61, 0x71, 0x6e,
+	0x52, 0x0c, 0xb7, 0xda, 0x55, 0xb7, 0x36, 0x44, 0x26, 0xe5, 0x17, 0xd9, 0x33, 0x3c, 0x65, 0xd2,
+	0x15, 0xfa, 0x29, 0xac, 0x57, 0xe4, 0x2a, 0xc5, 0xde, 0x9e, 0x4c, 0x58, 0x91, 0x32, 0xd9, 0x37,
+	0x7d, 0x08, 0x9b, 0x5e, 0xcc, 0xc7, 0x1c, 0x5f, 0x86, 0x87, 0x51, 0x18, 0x1d, 0x3e, 0xc3, 0xd3,
+	0xb9, 0xaa, 0x28, 0xbd, 0x0b, 0x8e, 0xbd, 0x50, 0x55, 0xc5, 0x89, 0xaa, 0x8a, 0x93, 0x30, 0xa0,
+	0x2f, 0x80, 0x78, 0xf8, 0x2e, 0x9e, 0xa0, 0x41, 0x7a, 0x7d, 0x33, 0x70, 0x0b, 0x2e, 0x89, 0x8f,
+	0xac, 0x28, 0x3e, 0x0d, 0xa3, 0x82, 0x76, 0x53, 0x48, 0xd7, 0xe1, 0xaa, 0x81, 0x28, 0x9b, 0x98,
+	0x57, 0xb0, 0xb1, 0x1b, 0xf1, 0x34, 0x66, 0x49, 0x56, 0x56, 0x3f, 0x96, 0xb1, 0x7f, 0x5a, 0xb0,
+	0x69, 0xc1, 0x4a, 0x67, 0xb3, 0xcc, 0xf1, 0x79, 0xf8, 0x2e, 0x3f, 0x8a, 0x1d, 0x4f, 0x8e, 0xb2,
+	0x6c, 0x95, 0xd5, 0x5d, 0x62, 0x16, 0xc3, 0xf3, 0x5b, 0x81, 0x8c, 0x7a, 0x5f, 0x24, 0xcf, 0x20,
+	0x49, 0xe4, 0x81, 0x54, 0x82, 0xc6, 0xa2, 0x25, 0x0a, 0x1d, 0x9b, 0x61, 0x30, 0xe0, 0xb2, 0x52,
+	0x95, 0x63, 0x2d, 0x95, 0x06, 0x5c, 0xd6, 0x24, 0x25, 0xc8, 0xb4, 0xa5, 0xc3, 0xf2, 0xba, 0x52,
+	0x02, 0xba, 0x0b, 0x9b, 0xcf, 0xe3, 0xc3, 0x78, 0xc6, 0x9f, 0xbc, 0xc3, 0xf4, 0xf4, 0xe4, 0x08,
+	0x53, 0xfc, 0xd0, 0xce, 0xd3, 0x05, 0xc7, 0x86, 0x92, 0xa1, 0xfb, 0x02, 0x7a, 0xb2, 0xd7, 0x1a,
+	0x4e, 0xc7, 0xe1, 0x31, 0x9b, 0x2f, 0x0f, 0xdf, 0xc0, 0x7a, 0x65, 0x95, 0x4a, 0x42, 0x36, 0xdb,
+	0x2f, 0x92, 0x90, 0xcd, 0xf6, 0xc9, 0x5d, 0x58, 0x4b, 0x52, 0x3c, 0xc0, 0x34, 0xc5, 0x60, 0xcf,
+	0xac, 0x31, 0xb6, 0xe2, 0xc1, 0x1f, 0x2b, 0x20, 0x1e, 0x32, 0xe4, 0x21, 0xac, 0x68, 0x0f, 0x19,
+	0xe2, 0xdc, 0xcb, 0xa4, 0xf7, 0xec, 0xb7, 0x8d, 0x7b, 0x39, 0xd7, 0x94, 0x33, 0xbf, 0x04, 0x50,
+	0xaf, 0x10, 0xb2, 0x99, 0x6b, 0xad, 0x77, 0x89, 0xb5, 0xec, 0x11, 0x80, 0x6a, 0xfc, 0x8b, 0x65,
+	0xd6, 0x5b, 0xc3, 0x75, 0x6c, 0x85, 0xf4, 0xfc, 0x11, 0x80, 0x6a, 0xef, 0x0b, 0x00, 0xeb, 0xd5,
+	0xe0, 0x3a, 0xb6, 0x42, 0x02, 0x3c, 0x81, 0xcb, 0x66, 0xfb, 0x4e, 0xd6, 0x4b, 0xa7, 0xf5, 0x33,
+	0xe5, 0x6e, 0x97, 0xe2, 0xba, 0xfe, 0x71, 0x24, 0x1e, 0x14, 0x7a, 0x43, 0xdb, 0x84, 0x73, 0xbd,
+	0x14, 0xd7, 0xb6, 0xbf, 0x6f, 0x80, 0x48, 0xb9, 0xbe, 0xa7, 0xff, 0xe5, 0x8b, 0x1a, 0x5b, 0x60,
+	0xb7, 0xdf, 0x3c, 0x41, 0x02, 0xff, 0x04, 0xbd, 0xba, 0xf6, 0x9e, 0xdc, 0x34, 0x62, 0x5c, 0xf7,
+	0xc6, 0x70, 0xe9, 0x59, 0x53, 0x24, 0xfc, 0xd7, 0xd0, 0x2d, 0xbb, 0x36, 0xb2, 0xa1, 0xc7, 0x5f,
+	0x35, 0xbb, 0xee, 0xa6, 0x25, 0x57, 0x61, 0x54, 0x1d, 0x59, 0x11, 0x46, 0xab, 0x91, 0x73, 0x1d,
+	0x5b, 0x51, 0x86, 0xf1, 0xa2, 0xde, 0x6a, 0x90, 0xad, 0x72, 0x66, 0xb5, 0x1b, 0x73, 0xdd, 0x3a,
+	0x95, 0x84, 0xf9, 0x11, 0x56, 0xab, 0x85, 0x9d, 0x5c, 0x37, 0xe3, 0x58, 0x69, 0x4e, 0xdc, 0x1b,
+	0x4d, 0x6a, 0x09, 0x39, 0x85, 0xad, 0xc6, 0xfa, 0x48, 0x6e, 0x97, 0x8b, 0xcf, 0x2c, 0xd9, 0xee,
+	0x27, 0xe7, 0xce, 0x93, 0xd6, 0x9e, 0xc2, 0x25, 0xa3, 0x20, 0x12, 0xe5, 0xad, 0x55, 0x3d, 0xdd,
+	0x6b, 0xb5, 0x3a, 0x45, 0x45, 0xb5, 0xe8, 0x15, 0x54, 0x34, 0x54, 0x51, 0xf7, 0x46, 0x93, 0x5a,
+	0x42, 0x3e, 0x86, 0x15, 0xad, 0x8e, 0x15, 0xb7, 0x8b, 0x5d, 0x2c, 0xdd, 0xad, 0x1a, 0x8d, 0xda,
+	0x56, 0xf5, 0x56, 0x2d, 0xb6, 0xd5, 0x70, 0x71, 0xbb, 0x37, 0x9a, 0xd4, 0x12, 0xf2, 0x7b, 0xb8,
+	0x52, 0x29, 0x78, 0x44, 0x1e, 0xf6, 0xfa, 0xf2, 0xea, 0x5e, 0x6f, 0xd0, 0x1a, 0x31, 0x50, 0xd7,
+	0xb4, 0x16, 0x03, 0xeb, 0xc6, 0x77, 0xaf, 0xd5, 0xea, 0x72, 0xa4, 0xfd, 0x65, 0xf1, 0x93, 0xe9,
+	0xf3, 0x7f, 0x07, 0x00, 0x34, 0xe7, 0x4f, 0xf6, 0x81, 0x12, 0x00, 0x00,
 }
@@ -0,0 +1,248 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keys requests are counted by
+const (
+	RateLimitByPeer = "peer"
+	RateLimitByApp  = "app"
+	RateLimitByUser = "user"
+)
+
+const (
+	rateLimitKeyPrefix = "ratelimit:"
+	// bucketSweepInterval is how often full buckets are removed from memory
+	bucketSweepInterval = time.Minute
+)
+
+var (
+	errUnexpectedReply = errors.New("unexpected reply of rate limit script")
+
+	statusRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded, retry later")
+)
+
+// RateLimit describes token bucket of a method: Requests are allowed per Period on average with bursts of up to
+// Burst requests
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is a bucket size, zero means Requests
+	Burst int
+	// Key is what requests are counted by: RateLimitByPeer, RateLimitByApp or RateLimitByUser. Requests without
+	// valid app id or user token are counted by client address
+	Key string
+}
+
+// DefaultRateLimits maps full method names to limits used unless other limits are configured, methods which are not
+// listed are not limited
+var DefaultRateLimits = map[string]RateLimit{
+	"/user.user/CreateUser":  {Requests: 10, Period: time.Minute, Burst: 20, Key: RateLimitByPeer},
+	"/user.user/CreateApp":   {Requests: 10, Period: time.Minute, Burst: 20, Key: RateLimitByPeer},
+	"/user.user/GetUserInfo": {Requests: 100, Period: time.Second, Burst: 200, Key: RateLimitByPeer},
+}
+
+// rate returns number of tokens added to bucket per second
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// RateLimiter keeps token buckets
+type RateLimiter interface {
+	// Allow takes a token from bucket identified by key, if bucket is empty it returns how long to wait for a token
+	Allow(key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt is when bucket is refilled, after that it is no different from a new one
+	fullAt time.Time
+}
+
+type memoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	sweptAt time.Time
+}
+
+// NewMemoryRateLimiter returns rate limiter which keeps buckets in process memory, limits are per replica
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		sweptAt: time.Now(),
+	}
+}
+
+func (l *memoryRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.sweptAt) > bucketSweepInterval {
+		for k, b := range l.buckets {
+			if now.After(b.fullAt) {
+				delete(l.buckets, k)
+			}
+		}
+
+		l.sweptAt = now
+	}
+
+	rate, burst := limit.rate(), limit.burst()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	tokens := math.Min(burst, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+	if tokens < 1 {
+		return false, time.Duration((1 - tokens) / rate * float64(time.Second)), nil
+	}
+
+	b.tokens = tokens - 1
+	b.updatedAt = now
+	b.fullAt = now.Add(time.Duration((burst - b.tokens) / rate * float64(time.Second)))
+	return true, 0, nil
+}
+
+// rateLimitScript takes a token from bucket stored in hash, time is in milliseconds. It replies with 1 if token was
+// taken, otherwise with 0 and number of milliseconds to wait
+var rateLimitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = burst
+if bucket[1] then
+	tokens = math.min(burst, tonumber(bucket[1]) + math.max(0, now - tonumber(bucket[2])) * rate)
+end
+if tokens < 1 then
+	return {0, math.ceil((1 - tokens) / rate)}
+end
+tokens = tokens - 1
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate))
+return {1, 0}
+`)
+
+type redisRateLimiter struct {
+	*redis.Client
+}
+
+// NewRedisRateLimiter returns rate limiter backed by redis database, buckets are shared by all replicas using it
+func NewRedisRateLimiter(addr, password string, db int) (RateLimiter, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	_, err := client.Ping().Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisRateLimiter{client}, nil
+}
+
+func (l *redisRateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("rate_limit"), time.Now())
+	// replicas pass their own clocks, small skew only shifts refill a little
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	reply, err := rateLimitScript.Run(l.Client, []string{rateLimitKeyPrefix + key}, limit.rate()/1000, limit.burst(), now).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, errUnexpectedReply
+	}
+
+	allowed, _ := values[0].(int64)
+	wait, _ := values[1].(int64)
+	return allowed == 1, time.Duration(wait) * time.Millisecond, nil
+}
+
+// tokenOwner returns uid of access token owner or empty string if token is invalid
+func (s *Server) tokenOwner(userToken string) string {
+	tokenID, err := s.accessTokenID(userToken)
+	if err != nil {
+		return ""
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err != nil {
+		return ""
+	}
+
+	return info.UID
+}
+
+// rateLimitKey returns key request is counted by
+func (s *Server) rateLimitKey(ctx context.Context, req interface{}, key string) string {
+	switch key {
+	case RateLimitByApp:
+		if r, ok := req.(interface{ GetAppUid() string }); ok {
+			// app ids are not verified here, but parsing at least stops clients from making up arbitrary keys
+			if appID, err := uuid.Parse(r.GetAppUid()); err == nil {
+				return "app:" + appID.String()
+			}
+		}
+	case RateLimitByUser:
+		if r, ok := req.(interface{ GetUserToken() string }); ok {
+			if uid := s.tokenOwner(r.GetUserToken()); uid != "" {
+				return "user:" + uid
+			}
+		}
+	}
+
+	// address is determined the same way as for login throttling
+	return "addr:" + clientAddress(ctx, s.loginLimits.TrustForwardedFor)
+}
+
+// limitRate rejects requests of methods with rate limits when their bucket is empty
+func (s *Server) limitRate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	limit, ok := s.rateLimits[info.FullMethod]
+	if !ok || limit.Requests <= 0 || limit.Period <= 0 {
+		return handler(ctx, req)
+	}
+
+	key := info.FullMethod + ":" + s.rateLimitKey(ctx, req, limit.Key)
+	allowed, wait, err := s.rateLimiter.Allow(key, limit)
+	if err != nil {
+		// limits protect service from overload, unavailable limiter is not a reason to reject requests
+		log.Printf("failed to check rate limit %s: %v", key, err)
+		return handler(ctx, req)
+	}
+
+	if !allowed {
+		rateLimited.WithLabelValues(info.FullMethod).Inc()
+		setRetryAfter(ctx, wait)
+		return nil, statusRateLimited
+	}
+
+	return handler(ctx, req)
+}
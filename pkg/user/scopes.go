@@ -0,0 +1,53 @@
+package user
+
+const (
+	// ScopeFullAccess is granted to tokens obtained with user password, it allows everything user can do.
+	// Apps can not request it
+	ScopeFullAccess = "*"
+	// ScopeOpenID makes authorization code flow return ID token and allows access to user info
+	ScopeOpenID = "openid"
+	// ScopeProfile allows access to username
+	ScopeProfile = "profile"
+)
+
+// isValidScope checks that scope is a scope-token as described in RFC 6749 section 3.3
+func isValidScope(scope string) bool {
+	if scope == "" || scope == ScopeFullAccess {
+		return false
+	}
+
+	for _, c := range scope {
+		if c < 0x21 || c == 0x22 || c == 0x5c || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasScope checks that scope is granted, full access includes every scope
+func hasScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == scope || s == ScopeFullAccess {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSubset checks that every scope in requested is present in allowed
+func isSubset(requested, allowed []string) bool {
+	set := make(map[string]struct{}, len(allowed))
+	for _, scope := range allowed {
+		set[scope] = struct{}{}
+	}
+
+	for _, scope := range requested {
+		if _, ok := set[scope]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
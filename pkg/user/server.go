@@ -1,84 +1,363 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"sync"
 
 	pb "github.com/andreymgn/RSOI-user/pkg/user/proto"
-	"github.com/go-redis/redis"
 	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // Server implements posts service
 type Server struct {
 	db                  datastore
-	accessTokenStorage  *redis.Client
-	refreshTokenStorage *redis.Client
-	oauthCodeStorage    *redis.Client
+	accessTokenStorage  TokenStore
+	refreshTokenStorage TokenStore
+	oauthCodeStorage    TokenStore
+	loginAttemptStorage TokenStore
+	signer              *JWTSigner
+	lifetimes           TokenLifetimes
+	grantLifetimes      map[string]TokenLifetimes
+	tls                 TLSConfig
+	hasher              PasswordHasher
+	dummyHash           string
+	passwordPolicy      PasswordPolicy
+	loginLimits         LoginLimits
+	rateLimits          map[string]RateLimit
+	rateLimiter         RateLimiter
+
+	securityEventHandler SecurityEventHandler
+	health               *healthState
+
+	mu          sync.Mutex
+	stopped     bool
+	grpcServer  *grpc.Server
+	httpServers []*http.Server
 }
 
-// NewServer returns a new server
-func NewServer(connString, redisAddr, redisPassword string, apiTokenDBNum int) (*Server, error) {
-	db, err := newDB(connString)
-	if err != nil {
-		return nil, err
+var (
+	errServerStopped = errors.New("server stopped")
+)
+
+// Option configures server
+type Option func(*Server)
+
+// WithAccessTokenStore sets storage of access tokens
+func WithAccessTokenStore(store TokenStore) Option {
+	return func(s *Server) {
+		s.accessTokenStorage = store
 	}
+}
 
-	accessTokenStorage := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       apiTokenDBNum,
-	})
+// WithRefreshTokenStore sets storage of refresh tokens
+func WithRefreshTokenStore(store TokenStore) Option {
+	return func(s *Server) {
+		s.refreshTokenStorage = store
+	}
+}
 
-	_, err = accessTokenStorage.Ping().Result()
-	if err != nil {
-		return nil, err
+// WithOAuthCodeStore sets storage of oauth codes
+func WithOAuthCodeStore(store TokenStore) Option {
+	return func(s *Server) {
+		s.oauthCodeStorage = store
 	}
+}
 
-	refreshTokenStorage := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       apiTokenDBNum + 1,
-	})
+// WithLoginAttemptStore sets storage of failed login counters
+func WithLoginAttemptStore(store TokenStore) Option {
+	return func(s *Server) {
+		s.loginAttemptStorage = store
+	}
+}
 
-	_, err = refreshTokenStorage.Ping().Result()
+// WithJWTSigner enables self-contained access tokens signed by signer
+func WithJWTSigner(signer *JWTSigner) Option {
+	return func(s *Server) {
+		s.signer = signer
+	}
+}
+
+// WithTokenLifetimes sets default lifetimes of tokens, zero fields keep built-in defaults
+func WithTokenLifetimes(lifetimes TokenLifetimes) Option {
+	return func(s *Server) {
+		s.lifetimes = s.lifetimes.override(lifetimes)
+	}
+}
+
+// WithGrantTokenLifetimes sets lifetimes of tokens issued with grant, zero fields are inherited from defaults
+func WithGrantTokenLifetimes(grant string, lifetimes TokenLifetimes) Option {
+	return func(s *Server) {
+		s.grantLifetimes[grant] = lifetimes
+	}
+}
+
+// WithTLS sets TLS settings, by default certificate is loaded from /cert.pem and /key.pem
+func WithTLS(config TLSConfig) Option {
+	return func(s *Server) {
+		s.tls = config
+	}
+}
+
+// WithPasswordHasher sets hasher of new passwords, by default bcrypt with DefaultBcryptCost is used.
+// Hashes made by other algorithms or with other parameters are still accepted and replaced on successful login
+func WithPasswordHasher(hasher PasswordHasher) Option {
+	return func(s *Server) {
+		s.hasher = hasher
+	}
+}
+
+// WithPasswordPolicy sets requirements to passwords of new users and changed passwords, by default
+// DefaultPasswordPolicy is used
+func WithPasswordPolicy(policy PasswordPolicy) Option {
+	return func(s *Server) {
+		s.passwordPolicy = policy
+	}
+}
+
+// WithLoginLimits sets throttling of failed logins, by default DefaultLoginLimits are used
+func WithLoginLimits(limits LoginLimits) Option {
+	return func(s *Server) {
+		s.loginLimits = limits
+	}
+}
+
+// WithRateLimits sets rate limits by full method name, by default DefaultRateLimits are used
+func WithRateLimits(limits map[string]RateLimit) Option {
+	return func(s *Server) {
+		s.rateLimits = limits
+	}
+}
+
+// WithRateLimiter sets storage of rate limit buckets, by default buckets are kept in memory
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(s *Server) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithSecurityEventHandler sets handler of security events, by default events are logged
+func WithSecurityEventHandler(handler SecurityEventHandler) Option {
+	return func(s *Server) {
+		s.securityEventHandler = handler
+	}
+}
+
+// newServer returns server using db with default settings and in-memory token stores
+func newServer(db datastore) *Server {
+	return &Server{
+		db:                  db,
+		accessTokenStorage:  NewMemoryTokenStore(),
+		refreshTokenStorage: NewMemoryTokenStore(),
+		oauthCodeStorage:    NewMemoryTokenStore(),
+		loginAttemptStorage: NewMemoryTokenStore(),
+		lifetimes: TokenLifetimes{
+			AccessToken:  AccessTokenExpirationTime,
+			RefreshToken: RefreshTokenExpirationTime,
+			OAuthCode:    OAuthCodeExpirationTime,
+			IDToken:      IDTokenExpirationTime,
+			Session:      MaxSessionTime,
+		},
+		grantLifetimes: make(map[string]TokenLifetimes),
+		tls:            TLSConfig{CertFile: "/cert.pem", KeyFile: "/key.pem"},
+		hasher:         BcryptHasher{Cost: DefaultBcryptCost},
+		passwordPolicy: DefaultPasswordPolicy,
+		loginLimits:    DefaultLoginLimits,
+		rateLimits:     DefaultRateLimits,
+		rateLimiter:    NewMemoryRateLimiter(),
+
+		securityEventHandler: logSecurityEvent,
+		health:               newHealthState(),
+	}
+}
+
+// NewServer returns a new server, token stores not set by options are kept in memory.
+// It fails if some migrations are not applied to database
+func NewServer(connString string, options ...Option) (*Server, error) {
+	db, err := newDB(connString)
 	if err != nil {
 		return nil, err
 	}
 
-	oauthCodeStorage := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       apiTokenDBNum + 2,
-	})
+	if err := checkSchema(db.DB); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	_, err = oauthCodeStorage.Ping().Result()
-	if err != nil {
+	s := newServer(db)
+	for _, option := range options {
+		option(s)
+	}
+
+	if err := s.makeDummyHash(); err != nil {
+		s.close()
 		return nil, err
 	}
 
-	return &Server{db, accessTokenStorage, refreshTokenStorage, oauthCodeStorage}, nil
+	return s, nil
 }
 
-// Start starts a server
+// chainUnaryInterceptors returns interceptor calling interceptors in order, the last one calls handler
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// Start starts a server, it returns nil after server is stopped
 func (s *Server) Start(port int, tracer opentracing.Tracer) error {
-	creds, err := credentials.NewServerTLSFromFile("/cert.pem", "/key.pem")
-	if err != nil {
-		return err
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(chainUnaryInterceptors(
+			otgrpc.OpenTracingServerInterceptor(tracer),
+			observeRPC,
+			s.limitRate,
+			s.requireClientCert,
+		)),
+	}
+
+	if !s.tls.Insecure {
+		certs, err := newCertReloader(s.tls)
+		if err != nil {
+			return err
+		}
+
+		options = append(options, grpc.Creds(credentials.NewTLS(certs.serverConfig([]string{"h2"}, true))))
 	}
 
-	server := grpc.NewServer(
-		grpc.Creds(creds),
-		grpc.UnaryInterceptor(otgrpc.OpenTracingServerInterceptor(tracer)),
-	)
+	server := grpc.NewServer(options...)
 	pb.RegisterUserServer(server, s)
+	healthpb.RegisterHealthServer(server, s.health.server)
+	if !s.setGRPCServer(server) {
+		return errServerStopped
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	return server.Serve(lis)
+	// dependencies are checked only once server is able to accept requests
+	s.startHealthChecks()
+	if err := server.Serve(lis); err != nil {
+		s.health.stop()
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) setGRPCServer(server *grpc.Server) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return false
+	}
+
+	s.grpcServer = server
+	return true
+}
+
+func (s *Server) addHTTPServer(server *http.Server) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return false
+	}
+
+	s.httpServers = append(s.httpServers, server)
+	return true
+}
+
+// Stop reports server as not serving, stops accepting new requests and waits for in-flight ones to finish.
+// When ctx is done remaining requests are cancelled. After servers are stopped database connection and token stores implementing io.Closer are closed
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return errServerStopped
+	}
+
+	s.stopped = true
+	grpcServer, httpServers := s.grpcServer, s.httpServers
+	s.mu.Unlock()
+
+	s.health.stop()
+
+	// gRPC and HTTP servers are stopped concurrently to share the same deadline
+	grpcStopped := make(chan struct{})
+	go func() {
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+
+		close(grpcStopped)
+	}()
+
+	var result error
+	for _, httpServer := range httpServers {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			httpServer.Close()
+			result = err
+		}
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+
+		<-grpcStopped
+		result = ctx.Err()
+	}
+
+	if err := s.close(); err != nil && result == nil {
+		result = err
+	}
+
+	return result
+}
+
+// close closes database connection, token stores and signing keys, stores shared by several token kinds are
+// closed once
+func (s *Server) close() error {
+	var result error
+	closed := make(map[io.Closer]bool)
+	resources := []interface{}{s.db, s.accessTokenStorage, s.refreshTokenStorage, s.oauthCodeStorage, s.loginAttemptStorage, s.rateLimiter}
+	if s.signer != nil {
+		resources = append(resources, s.signer)
+	}
+
+	for _, resource := range resources {
+		closer, ok := resource.(io.Closer)
+		if !ok || closed[closer] {
+			continue
+		}
+
+		closed[closer] = true
+		if err := closer.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,274 @@
+package user
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errFakeDB = errors.New("database is unavailable")
+
+type fakeUser struct {
+	User
+	normalized   string
+	passwordHash string
+}
+
+// fakeDB keeps users and apps in memory, when err is set every query fails with it
+type fakeDB struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*fakeUser
+	apps  map[uuid.UUID]*App
+	err   error
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		users: make(map[uuid.UUID]*fakeUser),
+		apps:  make(map[uuid.UUID]*App),
+	}
+}
+
+func (db *fakeDB) getUserInfo(uid uuid.UUID) (*User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return nil, db.err
+	}
+
+	u, ok := db.users[uid]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	result := u.User
+	return &result, nil
+}
+
+func (db *fakeDB) create(username, passwordHash string) (*User, error) {
+	normalized, err := normalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return nil, db.err
+	}
+
+	for _, u := range db.users {
+		if u.normalized == normalized {
+			return nil, errUserExists
+		}
+	}
+
+	u := &fakeUser{User{UID: uuid.New(), Username: username}, normalized, passwordHash}
+	db.users[u.UID] = u
+	result := u.User
+	return &result, nil
+}
+
+func (db *fakeDB) update(uid uuid.UUID, passwordHash string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return db.err
+	}
+
+	u, ok := db.users[uid]
+	if !ok {
+		return errNotFound
+	}
+
+	u.passwordHash = passwordHash
+	return nil
+}
+
+func (db *fakeDB) delete(uid uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return db.err
+	}
+
+	if _, ok := db.users[uid]; !ok {
+		return errNotFound
+	}
+
+	delete(db.users, uid)
+	return nil
+}
+
+func (db *fakeDB) getPasswordHash(uid uuid.UUID) (string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return "", db.err
+	}
+
+	u, ok := db.users[uid]
+	if !ok {
+		return "", errNotFound
+	}
+
+	return u.passwordHash, nil
+}
+
+func (db *fakeDB) replacePasswordHash(uid uuid.UUID, oldHash, newHash string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return db.err
+	}
+
+	if u, ok := db.users[uid]; ok && u.passwordHash == oldHash {
+		u.passwordHash = newHash
+	}
+
+	return nil
+}
+
+func (db *fakeDB) getUIDByUsername(username string) (uuid.UUID, error) {
+	normalized, _ := normalizeUsername(username)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return uuid.Nil, db.err
+	}
+
+	for _, u := range db.users {
+		if (u.normalized == "" && u.Username == username) || (u.normalized != "" && u.normalized == normalized) {
+			return u.UID, nil
+		}
+	}
+
+	return uuid.Nil, errNotFound
+}
+
+func (db *fakeDB) createApp(owner uuid.UUID, name string, redirectURIs []string, isPublic bool, scopes []string, lifetimes TokenLifetimes) (*App, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return nil, db.err
+	}
+
+	app := &App{uuid.New(), uuid.New(), owner, name, redirectURIs, isPublic, scopes, lifetimes}
+	db.apps[app.UID] = app
+	result := *app
+	return &result, nil
+}
+
+func (db *fakeDB) getAppInfo(appID uuid.UUID) (*AppInfo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return nil, db.err
+	}
+
+	app, ok := db.apps[appID]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	return &AppInfo{app.Owner, app.Name, app.IsPublic, app.Scopes, app.Lifetimes}, nil
+}
+
+func (db *fakeDB) isValidAppCredentials(appID, appSecret uuid.UUID) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return false, db.err
+	}
+
+	app, ok := db.apps[appID]
+	return ok && app.Secret == appSecret, nil
+}
+
+func (db *fakeDB) isRegisteredRedirectURI(appID uuid.UUID, redirectURI string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.err != nil {
+		return false, db.err
+	}
+
+	app, ok := db.apps[appID]
+	if !ok {
+		return false, errNotFound
+	}
+
+	for _, uri := range app.RedirectURIs {
+		if uri == redirectURI {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// newTestServer returns server with in-memory storage and the cheapest password hashing
+func newTestServer(t *testing.T, db *fakeDB) *Server {
+	s := newServer(db)
+	s.tls = TLSConfig{Insecure: true}
+	s.hasher = BcryptHasher{Cost: bcrypt.MinCost}
+	if err := s.makeDummyHash(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// addUser creates user with password and returns its uid
+func addUser(t *testing.T, s *Server, db *fakeDB, username, password string, isAdmin bool) uuid.UUID {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := db.create(username, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.users[user.UID].IsAdmin = isAdmin
+	return user.UID
+}
+
+// addApp creates app with redirect uri and returns it
+func addApp(t *testing.T, db *fakeDB, redirectURI string, isPublic bool, scopes []string) *App {
+	app, err := db.createApp(uuid.New(), "app", []string{redirectURI}, isPublic, scopes, TokenLifetimes{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+// issueUserToken returns access token of user with scopes
+func issueUserToken(t *testing.T, s *Server, uid uuid.UUID, scopes ...string) string {
+	token, _, err := s.issueAccessToken(&tokenInfo{
+		UID:         uid.String(),
+		SubjectType: SubjectTypeUser,
+		Scopes:      scopes,
+	}, s.lifetimes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
@@ -0,0 +1,192 @@
+package user
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// certCheckInterval is how often certificate files are checked for changes
+const certCheckInterval = 10 * time.Second
+
+var (
+	errInvalidClientCA = errors.New("no certificates found in client CA file")
+
+	statusClientCertRequired = status.Error(codes.PermissionDenied, "client certificate required")
+)
+
+// adminMethods can be called only by clients presenting certificate signed by client CA, without client CA they
+// can not be called over gRPC at all
+var adminMethods = map[string]bool{
+	"/user.user/RotateSigningKey": true,
+	"/user.user/IntrospectToken":  true,
+}
+
+// TLSConfig describes TLS settings of server
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables verification of client certificates, it is required to call admin RPCs
+	ClientCAFile string
+	// Insecure disables TLS, it should be used only for local development
+	Insecure bool
+}
+
+// certReloader keeps server certificate and client CAs up to date with files on disk
+type certReloader struct {
+	config TLSConfig
+
+	mu       sync.Mutex
+	checked  time.Time
+	modTimes map[string]time.Time
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+}
+
+func newCertReloader(config TLSConfig) (*certReloader, error) {
+	r := &certReloader{config: config}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	r.checked = time.Now()
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+
+	return files
+}
+
+// reload loads certificate and client CAs, must be called with mu held
+func (r *certReloader) reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCA *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return errInvalidClientCA
+		}
+	}
+
+	r.modTimes = modTimes
+	r.cert = &cert
+	r.clientCA = clientCA
+	return nil
+}
+
+// modified checks whether any file changed since last reload, must be called with mu held
+func (r *certReloader) modified() bool {
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// file may be in the middle of replacement, check again later
+			return false
+		}
+
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// current returns certificate and client CAs reloading them if files changed, on failure previous ones are kept
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checked) >= certCheckInterval {
+		r.checked = now
+		if r.modified() {
+			if err := r.reload(); err != nil {
+				log.Printf("failed to reload TLS certificate: %v", err)
+			}
+		}
+	}
+
+	return r.cert, r.clientCA
+}
+
+// serverConfig returns TLS config which uses up to date certificate for every connection
+func (r *certReloader) serverConfig(nextProtos []string, verifyClients bool) *tls.Config {
+	return &tls.Config{
+		// net/http requires certificate source in the base config
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, clientCA := r.current()
+			config := &tls.Config{
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   nextProtos,
+				MinVersion:   tls.VersionTLS12,
+			}
+
+			if verifyClients && clientCA != nil {
+				config.ClientCAs = clientCA
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+
+			return config, nil
+		},
+	}
+}
+
+// hasVerifiedClientCert checks that client presented certificate signed by client CA
+func hasVerifiedClientCert(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && len(info.State.VerifiedChains) > 0
+}
+
+// requireClientCert rejects calls of admin methods from clients without verified certificate. Clients can not be
+// verified without client CA, so calls are rejected then too
+func (s *Server) requireClientCert(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if adminMethods[info.FullMethod] && (s.tls.ClientCAFile == "" || s.tls.Insecure || !hasVerifiedClientCert(ctx)) {
+		return nil, statusClientCertRequired
+	}
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,429 @@
+package user
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	accessTokenTypeHint  = "access_token"
+	refreshTokenTypeHint = "refresh_token"
+)
+
+const (
+	// SubjectTypeUser is a type of tokens issued to users
+	SubjectTypeUser = "user"
+	// SubjectTypeApp is a type of tokens issued to apps acting on their own behalf
+	SubjectTypeApp = "app"
+)
+
+// tokenInfo describes access or refresh token saved to storage
+type tokenInfo struct {
+	// UID is uid of user or app token was issued to
+	UID         string `json:"uid"`
+	SubjectType string `json:"subject_type,omitempty"`
+	// ClientApp is uid of app token was issued to, it is empty for tokens obtained with user password
+	ClientApp string `json:"client_app,omitempty"`
+	// IssuedAt is a unix time when token was issued, it is zero for tokens issued before it was recorded
+	IssuedAt int64 `json:"iat,omitempty"`
+	// Grant is a grant token was obtained with, tokens obtained by refreshing keep original grant
+	Grant string `json:"grant,omitempty"`
+	// SessionExpiresAt is a unix time after which token can not be extended or refreshed, zero means never
+	SessionExpiresAt int64 `json:"session_exp,omitempty"`
+	// Family groups refresh tokens obtained by rotating the same original token and access tokens issued with them
+	Family string   `json:"family,omitempty"`
+	Scopes []string `json:"scopes"`
+}
+
+// rotationInfo is saved to marker of refresh token which was exchanged for a new one
+type rotationInfo struct {
+	Family    string `json:"family"`
+	RotatedAt int64  `json:"rotated_at"`
+}
+
+// isTokenKey checks that key has format of token, so indexes and markers kept in the same storage
+// are never taken for tokens
+func isTokenKey(key string) bool {
+	id, err := uuid.Parse(key)
+	return err == nil && id.String() == key
+}
+
+// parseTokenInfo decodes token info, tokens issued before token info was introduced contain only owner uid.
+// Such tokens and tokens issued before scopes were introduced are user tokens with full access, so they are
+// accepted only if user still exists
+func (s *Server) parseTokenInfo(value string) (*tokenInfo, error) {
+	info := new(tokenInfo)
+	if err := json.Unmarshal([]byte(value), info); err != nil {
+		uid, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errTokenNotFound
+		}
+
+		if _, err := s.db.getUserInfo(uid); err == errNotFound {
+			return nil, errTokenNotFound
+		} else if err != nil {
+			return nil, err
+		}
+
+		info = &tokenInfo{UID: uid.String()}
+	}
+
+	if info.UID == "" {
+		return nil, errTokenNotFound
+	}
+
+	if info.SubjectType == "" {
+		info.SubjectType = SubjectTypeUser
+	}
+
+	if info.Scopes == nil {
+		info.Scopes = []string{ScopeFullAccess}
+	}
+
+	return info, nil
+}
+
+// userTokensIndex returns name of index containing all tokens of user
+func userTokensIndex(uid string) string {
+	return "user:" + uid
+}
+
+// familyIndex returns name of index containing all tokens of token family
+func familyIndex(family string) string {
+	return "family:" + family
+}
+
+// rotatedMarker returns key which is set when refresh token is exchanged for a new one
+func rotatedMarker(token string) string {
+	return "rotated:" + token
+}
+
+// issueAccessToken creates access token and saves it to storage, it returns token and its lifetime
+func (s *Server) issueAccessToken(info *tokenInfo, lifetimes TokenLifetimes) (string, time.Duration, error) {
+	expiration, err := info.expiration(lifetimes.AccessToken)
+	if err != nil {
+		return "", 0, err
+	}
+
+	issued := *info
+	issued.IssuedAt = time.Now().Unix()
+	value, err := json.Marshal(&issued)
+	if err != nil {
+		return "", 0, err
+	}
+
+	id := uuid.New().String()
+	token := id
+	if s.signer != nil {
+		expiration = s.signer.limitLifetime(expiration)
+		isAdmin := false
+		if info.SubjectType == SubjectTypeUser {
+			uid, err := uuid.Parse(info.UID)
+			if err != nil {
+				return "", 0, err
+			}
+
+			user, err := s.db.getUserInfo(uid)
+			if err != nil {
+				return "", 0, err
+			}
+
+			isAdmin = user.IsAdmin
+		}
+
+		token, err = s.signer.sign(id, info, isAdmin, expiration)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	err = s.accessTokenStorage.Set(id, string(value), expiration)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// app tokens are short-lived and not bound to user, so they are not indexed
+	if info.SubjectType == SubjectTypeApp {
+		tokensIssued.WithLabelValues(accessTokenTypeHint).Inc()
+		return token, expiration, nil
+	}
+
+	err = s.accessTokenStorage.AddToIndex(userTokensIndex(info.UID), id, expiration)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if info.Family != "" {
+		// family index lives as long as refresh tokens of the family
+		err = s.accessTokenStorage.AddToIndex(familyIndex(info.Family), id, lifetimes.RefreshToken)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	tokensIssued.WithLabelValues(accessTokenTypeHint).Inc()
+	return token, expiration, nil
+}
+
+// issueRefreshToken creates refresh token of token family and saves it to storage
+func (s *Server) issueRefreshToken(info *tokenInfo, lifetimes TokenLifetimes) (string, error) {
+	expiration, err := info.expiration(lifetimes.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	issued := *info
+	issued.IssuedAt = time.Now().Unix()
+	value, err := json.Marshal(&issued)
+	if err != nil {
+		return "", err
+	}
+
+	token := uuid.New().String()
+	err = s.refreshTokenStorage.Set(token, string(value), expiration)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.refreshTokenStorage.AddToIndex(userTokensIndex(info.UID), token, expiration)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.refreshTokenStorage.AddToIndex(familyIndex(info.Family), token, expiration)
+	if err != nil {
+		return "", err
+	}
+
+	tokensIssued.WithLabelValues(refreshTokenTypeHint).Inc()
+	return token, nil
+}
+
+// getAccessToken returns access token info by token key in storage or errTokenNotFound
+func (s *Server) getAccessToken(tokenID string) (*tokenInfo, error) {
+	if !isTokenKey(tokenID) {
+		return nil, errTokenNotFound
+	}
+
+	value, err := s.accessTokenStorage.Get(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseTokenInfo(value)
+}
+
+// getRefreshToken returns refresh token info or errTokenNotFound
+func (s *Server) getRefreshToken(token string) (*tokenInfo, error) {
+	if !isTokenKey(token) {
+		return nil, errTokenNotFound
+	}
+
+	value, err := s.refreshTokenStorage.Get(token)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.parseTokenInfo(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Family == "" {
+		info.Family = token
+	}
+
+	return info, nil
+}
+
+// extendAccessToken restarts lifetime of access token, token is never extended beyond the end of its session.
+// Only stored lifetime is extended, so it must not be used for signed tokens
+func (s *Server) extendAccessToken(tokenID string, info *tokenInfo) error {
+	lifetimes, err := s.appLifetimesFor(info.Grant, info.ClientApp)
+	if err != nil {
+		return err
+	}
+
+	expiration, err := info.expiration(lifetimes.AccessToken)
+	if err == errSessionExpired {
+		// token lives until its current expiration time
+		return nil
+	}
+
+	ttl, err := s.accessTokenStorage.TTL(tokenID)
+	if err != nil {
+		return err
+	}
+
+	if ttl >= expiration {
+		return nil
+	}
+
+	if err := s.accessTokenStorage.Expire(tokenID, expiration); err != nil {
+		return err
+	}
+
+	// keep index alive as long as the token it contains
+	if info.SubjectType == SubjectTypeUser {
+		return s.accessTokenStorage.AddToIndex(userTokensIndex(info.UID), tokenID, expiration)
+	}
+
+	return nil
+}
+
+// lookupAccessToken returns info and remaining lifetime of access token or errTokenNotFound
+func (s *Server) lookupAccessToken(token string) (*tokenInfo, time.Duration, error) {
+	tokenID, err := s.accessTokenID(token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ttl, err := s.accessTokenStorage.TTL(tokenID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return info, ttl, nil
+}
+
+// lookupRefreshToken returns info and remaining lifetime of refresh token which was not exchanged yet or errTokenNotFound
+func (s *Server) lookupRefreshToken(token string) (*tokenInfo, time.Duration, error) {
+	info, err := s.getRefreshToken(token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = s.refreshTokenStorage.Get(rotatedMarker(token))
+	if err == nil {
+		return nil, 0, errTokenNotFound
+	} else if err != errTokenNotFound {
+		return nil, 0, err
+	}
+
+	ttl, err := s.refreshTokenStorage.TTL(token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return info, ttl, nil
+}
+
+// rotateRefreshToken marks refresh token of family as exchanged, returns false if it was already exchanged
+// marker is kept for expiration, which must be at least remaining lifetime of the token
+func (s *Server) rotateRefreshToken(token, family string, expiration time.Duration) (bool, error) {
+	value, err := json.Marshal(&rotationInfo{Family: family, RotatedAt: time.Now().Unix()})
+	if err != nil {
+		return false, err
+	}
+
+	return s.refreshTokenStorage.SetIfNotExists(rotatedMarker(token), string(value), expiration)
+}
+
+// accessTokenID returns key of access token in storage, self-contained tokens are verified first
+func (s *Server) accessTokenID(token string) (string, error) {
+	if s.signer == nil || !strings.Contains(token, ".") {
+		return token, nil
+	}
+
+	claims, err := s.signer.verify(token)
+	if err != nil {
+		return "", errTokenNotFound
+	}
+
+	return claims.Id, nil
+}
+
+// revokeAccessToken deletes access token, returns false if token does not exist
+func (s *Server) revokeAccessToken(token string) (bool, error) {
+	tokenID, err := s.accessTokenID(token)
+	if err != nil {
+		return false, nil
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err == errTokenNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := s.accessTokenStorage.Delete(tokenID); err != nil {
+		return false, err
+	}
+
+	tokensRevoked.WithLabelValues(revokeToken).Inc()
+
+	return true, s.accessTokenStorage.RemoveFromIndex(userTokensIndex(info.UID), tokenID)
+}
+
+// revokeRefreshToken deletes whole family of refresh token, returns false if token does not exist
+func (s *Server) revokeRefreshToken(token string) (bool, error) {
+	info, err := s.getRefreshToken(token)
+	if err == errTokenNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, s.revokeFamily(info.Family)
+}
+
+// revokeFamily deletes all access and refresh tokens of token family with rotation markers of refresh tokens
+func (s *Server) revokeFamily(family string) error {
+	if err := s.revokeIndexes(familyIndex(family)); err != nil {
+		return err
+	}
+
+	tokensRevoked.WithLabelValues(revokeFamily).Inc()
+	return nil
+}
+
+// revokeUserTokens deletes all access and refresh tokens of user with rotation markers of refresh tokens
+func (s *Server) revokeUserTokens(uid string) error {
+	if err := s.revokeIndexes(userTokensIndex(uid)); err != nil {
+		return err
+	}
+
+	tokensRevoked.WithLabelValues(revokeUser).Inc()
+	return nil
+}
+
+// revokeIndexes deletes tokens from index of the same name in access and refresh token storages
+func (s *Server) revokeIndexes(index string) error {
+	if err := revokeIndex(s.accessTokenStorage, index, nil); err != nil {
+		return err
+	}
+
+	return revokeIndex(s.refreshTokenStorage, index, rotatedMarker)
+}
+
+// revokeIndex deletes all tokens from index and the index itself, if marker is not nil keys returned by it
+// for tokens are deleted too
+func revokeIndex(storage TokenStore, index string, marker func(string) string) error {
+	tokens, err := storage.Index(index)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if err := storage.Delete(token); err != nil {
+			return err
+		}
+
+		if marker != nil {
+			if err := storage.Delete(marker(token)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return storage.Delete(index)
+}
@@ -0,0 +1,421 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// tokenSweepInterval is how often expired tokens are removed from memory token store
+const tokenSweepInterval = time.Minute
+
+var (
+	errTokenNotFound = errors.New("token not found")
+)
+
+// TokenStore describes storage of tokens with limited lifetime
+type TokenStore interface {
+	// Set stores value under token, zero expiration means token never expires
+	Set(token, value string, expiration time.Duration) error
+	// SetIfNotExists stores value under token only if token does not exist yet
+	SetIfNotExists(token, value string, expiration time.Duration) (bool, error)
+	// Get returns value stored under token or errTokenNotFound
+	Get(token string) (string, error)
+	// Take returns value stored under token or errTokenNotFound and deletes token, when it is called concurrently
+	// only one caller gets the value
+	Take(token string) (string, error)
+	// Increment increases integer stored under token by one and returns new value, missing token is treated as zero.
+	// Expiration is updated on every call
+	Increment(token string, expiration time.Duration) (int64, error)
+	// Expire updates token expiration time
+	Expire(token string, expiration time.Duration) error
+	// TTL returns remaining lifetime of token or errTokenNotFound, zero means token never expires
+	TTL(token string) (time.Duration, error)
+	// Delete removes token or index
+	Delete(token string) error
+	// AddToIndex adds token to index, index is kept for at least expiration
+	AddToIndex(index, token string, expiration time.Duration) error
+	// RemoveFromIndex removes token from index
+	RemoveFromIndex(index, token string) error
+	// Index returns tokens added to index, some of them may be already expired
+	Index(index string) ([]string, error)
+}
+
+type redisTokenStore struct {
+	*redis.Client
+}
+
+// NewRedisTokenStore returns token store backed by redis database
+func NewRedisTokenStore(addr, password string, db int) (TokenStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	_, err := client.Ping().Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisTokenStore{client}, nil
+}
+
+func (s *redisTokenStore) Set(token, value string, expiration time.Duration) error {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("set"), time.Now())
+	return s.Client.Set(token, value, expiration).Err()
+}
+
+func (s *redisTokenStore) SetIfNotExists(token, value string, expiration time.Duration) (bool, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("set_if_not_exists"), time.Now())
+	return s.Client.SetNX(token, value, expiration).Result()
+}
+
+func (s *redisTokenStore) Get(token string) (string, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("get"), time.Now())
+	value, err := s.Client.Get(token).Result()
+	if err == redis.Nil {
+		return "", errTokenNotFound
+	}
+
+	return value, err
+}
+
+func (s *redisTokenStore) Take(token string) (string, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("take"), time.Now())
+	pipe := s.Client.TxPipeline()
+	get := pipe.Get(token)
+	pipe.Del(token)
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	value, err := get.Result()
+	if err == redis.Nil {
+		return "", errTokenNotFound
+	}
+
+	return value, err
+}
+
+func (s *redisTokenStore) Increment(token string, expiration time.Duration) (int64, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("increment"), time.Now())
+	pipe := s.Client.TxPipeline()
+	incr := pipe.Incr(token)
+	if expiration > 0 {
+		pipe.Expire(token, expiration)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (s *redisTokenStore) Expire(token string, expiration time.Duration) error {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("expire"), time.Now())
+	ok, err := s.Client.Expire(token, expiration).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errTokenNotFound
+	}
+
+	return nil
+}
+
+func (s *redisTokenStore) TTL(token string) (time.Duration, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("ttl"), time.Now())
+	ttl, err := s.Client.TTL(token).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// redis replies -2 when key does not exist and -1 when key has no expiration
+	switch ttl {
+	case -2 * time.Second:
+		return 0, errTokenNotFound
+	case -1 * time.Second:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (s *redisTokenStore) Delete(token string) error {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("delete"), time.Now())
+	return s.Client.Del(token).Err()
+}
+
+func (s *redisTokenStore) AddToIndex(index, token string, expiration time.Duration) error {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("add_to_index"), time.Now())
+	pipe := s.Client.TxPipeline()
+	pipe.SAdd(index, token)
+	ttl := pipe.TTL(index)
+	_, err := pipe.Exec()
+	if err != nil {
+		return err
+	}
+
+	if expiration > 0 && ttl.Val() < expiration {
+		return s.Client.Expire(index, expiration).Err()
+	}
+
+	return nil
+}
+
+func (s *redisTokenStore) RemoveFromIndex(index, token string) error {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("remove_from_index"), time.Now())
+	return s.Client.SRem(index, token).Err()
+}
+
+func (s *redisTokenStore) Index(index string) ([]string, error) {
+	defer observeDuration(tokenStoreDuration.WithLabelValues("index"), time.Now())
+	return s.Client.SMembers(index).Result()
+}
+
+type memoryToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (t memoryToken) expired(now time.Time) bool {
+	return !t.expiresAt.IsZero() && now.After(t.expiresAt)
+}
+
+type memoryIndex struct {
+	tokens    map[string]struct{}
+	expiresAt time.Time
+}
+
+func (idx *memoryIndex) expired(now time.Time) bool {
+	return !idx.expiresAt.IsZero() && now.After(idx.expiresAt)
+}
+
+// memoryTokenStore keeps tokens in maps, expired tokens are removed when they are looked up and by a sweep on
+// writes, so tokens which are never read again, like failed login counters, do not pile up
+type memoryTokenStore struct {
+	mu      sync.Mutex
+	tokens  map[string]memoryToken
+	indexes map[string]*memoryIndex
+	sweptAt time.Time
+}
+
+// NewMemoryTokenStore returns token store which keeps tokens in process memory
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{
+		tokens:  make(map[string]memoryToken),
+		indexes: make(map[string]*memoryIndex),
+		sweptAt: time.Now(),
+	}
+}
+
+func expirationTime(now time.Time, expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(expiration)
+}
+
+func (s *memoryTokenStore) Set(token, value string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	s.tokens[token] = memoryToken{value, expirationTime(now, expiration)}
+	return nil
+}
+
+func (s *memoryTokenStore) SetIfNotExists(token, value string, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if _, ok := s.lookup(token); ok {
+		return false, nil
+	}
+
+	s.tokens[token] = memoryToken{value, expirationTime(now, expiration)}
+	return true, nil
+}
+
+func (s *memoryTokenStore) Get(token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.lookup(token)
+	if !ok {
+		return "", errTokenNotFound
+	}
+
+	return t.value, nil
+}
+
+func (s *memoryTokenStore) Take(token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.lookup(token)
+	if !ok {
+		return "", errTokenNotFound
+	}
+
+	delete(s.tokens, token)
+	return t.value, nil
+}
+
+func (s *memoryTokenStore) Increment(token string, expiration time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	var value int64
+	if t, ok := s.lookup(token); ok {
+		var err error
+		value, err = strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	value++
+	s.tokens[token] = memoryToken{strconv.FormatInt(value, 10), expirationTime(now, expiration)}
+	return value, nil
+}
+
+func (s *memoryTokenStore) Expire(token string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.lookup(token)
+	if !ok {
+		return errTokenNotFound
+	}
+
+	t.expiresAt = expirationTime(time.Now(), expiration)
+	s.tokens[token] = t
+	return nil
+}
+
+func (s *memoryTokenStore) TTL(token string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.lookup(token)
+	if !ok {
+		return 0, errTokenNotFound
+	}
+
+	if t.expiresAt.IsZero() {
+		return 0, nil
+	}
+
+	return time.Until(t.expiresAt), nil
+}
+
+func (s *memoryTokenStore) Delete(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, token)
+	delete(s.indexes, token)
+	return nil
+}
+
+func (s *memoryTokenStore) AddToIndex(index, token string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	expiresAt := expirationTime(now, expiration)
+	idx, ok := s.indexes[index]
+	if !ok || idx.expired(now) {
+		idx = &memoryIndex{tokens: make(map[string]struct{}), expiresAt: expiresAt}
+		s.indexes[index] = idx
+	} else if !idx.expiresAt.IsZero() && (expiresAt.IsZero() || expiresAt.After(idx.expiresAt)) {
+		idx.expiresAt = expiresAt
+	}
+
+	idx.tokens[token] = struct{}{}
+	return nil
+}
+
+func (s *memoryTokenStore) RemoveFromIndex(index, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx, ok := s.indexes[index]; ok {
+		delete(idx.tokens, token)
+	}
+
+	return nil
+}
+
+func (s *memoryTokenStore) Index(index string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, ok := s.indexes[index]
+	if !ok {
+		return nil, nil
+	}
+
+	if idx.expired(time.Now()) {
+		delete(s.indexes, index)
+		return nil, nil
+	}
+
+	result := make([]string, 0, len(idx.tokens))
+	for token := range idx.tokens {
+		result = append(result, token)
+	}
+
+	return result, nil
+}
+
+// sweep removes expired tokens and indexes once in tokenSweepInterval, must be called with mu held
+func (s *memoryTokenStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) <= tokenSweepInterval {
+		return
+	}
+
+	for token, t := range s.tokens {
+		if t.expired(now) {
+			delete(s.tokens, token)
+		}
+	}
+
+	for index, idx := range s.indexes {
+		if idx.expired(now) {
+			delete(s.indexes, index)
+		}
+	}
+
+	s.sweptAt = now
+}
+
+// lookup returns token if it exists and is not expired, must be called with mu held
+func (s *memoryTokenStore) lookup(token string) (memoryToken, bool) {
+	t, ok := s.tokens[token]
+	if !ok {
+		return t, false
+	}
+
+	if t.expired(time.Now()) {
+		delete(s.tokens, token)
+		return t, false
+	}
+
+	return t, true
+}
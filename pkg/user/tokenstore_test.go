@@ -0,0 +1,45 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenStoreSweep(t *testing.T) {
+	s := NewMemoryTokenStore().(*memoryTokenStore)
+	if _, err := s.Increment("login:10.0.0.1", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddToIndex("index", "token", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Set("kept", "value", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	// expired tokens stay until sweep is due
+	if err := s.Set("token", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.tokens) != 3 || len(s.indexes) != 1 {
+		t.Fatalf("before sweep: %d tokens and %d indexes, want 3 and 1", len(s.tokens), len(s.indexes))
+	}
+
+	s.sweptAt = time.Now().Add(-2 * tokenSweepInterval)
+	if _, err := s.Increment("login:10.0.0.2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.tokens) != 3 || len(s.indexes) != 0 {
+		t.Errorf("after sweep: %d tokens and %d indexes, want 3 and 0", len(s.tokens), len(s.indexes))
+	}
+
+	if _, err := s.Get("kept"); err != nil {
+		t.Errorf("unexpired token was swept: %v", err)
+	}
+}
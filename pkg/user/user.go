@@ -4,8 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/go-redis/redis"
-
 	pb "github.com/andreymgn/RSOI-user/pkg/user/proto"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -16,19 +14,88 @@ const (
 	AccessTokenExpirationTime  = time.Minute * 15
 	RefreshTokenExpirationTime = time.Hour * 24 * 7 * 2
 	OAuthCodeExpirationTime    = time.Minute
+	IDTokenExpirationTime      = time.Hour
+	MaxSessionTime             = time.Hour * 24 * 30
 )
 
 var (
-	statusNotFound         = status.Error(codes.NotFound, "user not found")
-	statusInvalidUUID      = status.Error(codes.InvalidArgument, "invalid UUID")
-	statusInvalidUserToken = status.Error(codes.Unauthenticated, "invalid user token")
-	statusUserExists       = status.Error(codes.AlreadyExists, "user already exists")
+	statusNotFound              = status.Error(codes.NotFound, "user not found")
+	statusInvalidUUID           = status.Error(codes.InvalidArgument, "invalid UUID")
+	statusInvalidUserToken      = status.Error(codes.Unauthenticated, "invalid user token")
+	statusUserExists            = status.Error(codes.AlreadyExists, "user already exists")
+	statusForbidden             = status.Error(codes.PermissionDenied, "forbidden")
+	statusSigningDisabled       = status.Error(codes.FailedPrecondition, "token signing is disabled")
+	statusCannotExtend          = status.Error(codes.FailedPrecondition, "signed access tokens can not be extended, refresh them instead")
+	statusInvalidRedirect       = status.Error(codes.InvalidArgument, "invalid redirect uri")
+	statusInvalidChallenge      = status.Error(codes.InvalidArgument, "invalid code challenge")
+	statusInvalidVerifier       = status.Error(codes.Unauthenticated, "invalid code verifier")
+	statusInvalidScope          = status.Error(codes.InvalidArgument, "invalid scope")
+	statusInsufficientScope     = status.Error(codes.PermissionDenied, "insufficient scope")
+	statusInvalidAppCredentials = status.Error(codes.Unauthenticated, "wrong appid appsecret pair")
+	statusUnauthorizedClient    = status.Error(codes.Unauthenticated, "public apps can not use client credentials")
 )
 
 func internalError(err error) error {
 	return status.Error(codes.Internal, err.Error())
 }
 
+// accessTokenOwner returns uid of access token owner, token must be granted full access
+func (s *Server) accessTokenOwner(userToken string) (uuid.UUID, error) {
+	tokenID, err := s.accessTokenID(userToken)
+	if err != nil {
+		return uuid.Nil, statusInvalidUserToken
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err == errTokenNotFound {
+		return uuid.Nil, statusInvalidUserToken
+	} else if err != nil {
+		return uuid.Nil, internalError(err)
+	}
+
+	ownerUID, err := uuid.Parse(info.UID)
+	if err != nil {
+		return uuid.Nil, statusInvalidUserToken
+	}
+
+	if !hasScope(info.Scopes, ScopeFullAccess) {
+		return uuid.Nil, statusInsufficientScope
+	}
+
+	return ownerUID, nil
+}
+
+// checkAdmin checks that user is admin
+func (s *Server) checkAdmin(uid uuid.UUID) error {
+	user, err := s.db.getUserInfo(uid)
+	switch err {
+	case nil:
+		if !user.IsAdmin {
+			return statusForbidden
+		}
+
+		return nil
+	case errNotFound:
+		return statusInvalidUserToken
+	default:
+		return internalError(err)
+	}
+}
+
+// checkPermission checks that owner of user token is allowed to modify user with given uid
+func (s *Server) checkPermission(userToken string, uid uuid.UUID) error {
+	ownerUID, err := s.accessTokenOwner(userToken)
+	if err != nil {
+		return err
+	}
+
+	if ownerUID == uid {
+		return nil
+	}
+
+	return s.checkAdmin(ownerUID)
+}
+
 // UserInfo converts User to protobuf struct
 func (u *User) UserInfo() *pb.UserInfo {
 	result := new(pb.UserInfo)
@@ -58,15 +125,20 @@ func (s *Server) GetUserInfo(ctx context.Context, req *pb.GetUserInfoRequest) (*
 
 // CreateUser creates a new user
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.UserInfo, error) {
-	if len(req.Username) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "username is empty")
+	if err := checkUsername(req.Username); err != nil {
+		return nil, err
+	}
+
+	if err := s.passwordPolicy.check(req.Username, req.Password); err != nil {
+		return nil, err
 	}
 
-	if len(req.Password) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "password is empty")
+	passwordHash, err := s.hashPassword(req.Password)
+	if err != nil {
+		return nil, internalError(err)
 	}
 
-	user, err := s.db.create(req.Username, req.Password)
+	user, err := s.db.create(req.Username, passwordHash)
 	switch err {
 	case nil:
 		return user.UserInfo(), nil
@@ -84,9 +156,33 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 		return nil, statusInvalidUUID
 	}
 
-	err = s.db.update(uid, req.Password)
+	if err := s.checkPermission(req.UserToken, uid); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.getUserInfo(uid)
+	if err == errNotFound {
+		return nil, statusNotFound
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	if err := s.passwordPolicy.check(user.Username, req.Password); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := s.hashPassword(req.Password)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	err = s.db.update(uid, passwordHash)
 	switch err {
 	case nil:
+		if err := s.revokeUserTokens(uid.String()); err != nil {
+			return nil, internalError(err)
+		}
+
 		return new(pb.UpdateUserResponse), nil
 	case errNotFound:
 		return nil, statusNotFound
@@ -102,9 +198,17 @@ func (s *Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb
 		return nil, statusInvalidUUID
 	}
 
+	if err := s.checkPermission(req.UserToken, uid); err != nil {
+		return nil, err
+	}
+
 	err = s.db.delete(uid)
 	switch err {
 	case nil:
+		if err := s.revokeUserTokens(uid.String()); err != nil {
+			return nil, internalError(err)
+		}
+
 		return new(pb.DeleteUserResponse), nil
 	case errNotFound:
 		return nil, statusNotFound
@@ -115,26 +219,19 @@ func (s *Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb
 
 // GetAccessToken returns authorization token for user
 func (s *Server) GetAccessToken(ctx context.Context, req *pb.GetTokenRequest) (*pb.GetAccessTokenResponse, error) {
-	uid, err := s.db.getUIDByUsername(req.Username)
-	if err == errNotFound {
-		return nil, statusNotFound
-	} else if err != nil {
-		return nil, internalError(err)
-	}
-
-	samePassword, err := s.db.checkPassword(uid, req.Password)
-	if err == errNotFound {
-		return nil, statusNotFound
-	} else if err != nil {
-		return nil, internalError(err)
-	}
-
-	if !samePassword {
-		return nil, status.Error(codes.Unauthenticated, "wrong password")
+	uid, err := s.authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, err
 	}
 
-	token := uuid.New().String()
-	err = s.accessTokenStorage.Set(token, uid.String(), AccessTokenExpirationTime).Err()
+	lifetimes := s.lifetimesFor(GrantPassword, nil)
+	token, expiration, err := s.issueAccessToken(&tokenInfo{
+		UID:              uid.String(),
+		SubjectType:      SubjectTypeUser,
+		Scopes:           []string{ScopeFullAccess},
+		Grant:            GrantPassword,
+		SessionExpiresAt: lifetimes.sessionExpiresAt(),
+	}, lifetimes)
 	if err != nil {
 		return nil, internalError(err)
 	}
@@ -142,49 +239,66 @@ func (s *Server) GetAccessToken(ctx context.Context, req *pb.GetTokenRequest) (*
 	res := new(pb.GetAccessTokenResponse)
 	res.Token = token
 	res.Uid = uid.String()
+	res.ExpiresIn = int64(expiration.Seconds())
 	return res, nil
 }
 
-// GetUserByAccessToken checks access token existance and refreshes token expiration time
+// GetUserByAccessToken checks access token existance, token expiration time is refreshed only when requested
+// and never beyond the end of token session. Expiration time of signed tokens is a part of token itself,
+// so extension is rejected when token signing is enabled
 func (s *Server) GetUserByAccessToken(ctx context.Context, req *pb.GetUserByAccessTokenRequest) (*pb.GetUserByAccessTokenResponse, error) {
-	token := req.UserToken
-	uid, err := s.accessTokenStorage.Get(token).Result()
-	if err == redis.Nil {
+	tokenID, err := s.accessTokenID(req.UserToken)
+	if err != nil {
+		return nil, statusInvalidUserToken
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err == errTokenNotFound {
 		return nil, statusInvalidUserToken
 	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	if _, err := uuid.Parse(uid); err != nil {
+	if _, err := uuid.Parse(info.UID); err != nil {
 		return nil, statusInvalidUserToken
 	}
 
-	err = s.accessTokenStorage.Expire(token, AccessTokenExpirationTime).Err()
-	if err != nil {
-		return nil, internalError(err)
+	if req.ExtendExpiration {
+		if s.signer != nil {
+			return nil, statusCannotExtend
+		}
+
+		err := s.extendAccessToken(tokenID, info)
+		if err == errTokenNotFound || err == errNotFound {
+			return nil, statusInvalidUserToken
+		} else if err != nil {
+			return nil, internalError(err)
+		}
 	}
 
 	res := new(pb.GetUserByAccessTokenResponse)
-	res.Uid = uid
+	res.Uid = info.UID
+	res.Scopes = info.Scopes
+	res.SubjectType = info.SubjectType
 	return res, nil
 }
 
 // GetRefreshToken returns token which can be used to refresh access token
 func (s *Server) GetRefreshToken(ctx context.Context, req *pb.GetTokenRequest) (*pb.GetRefreshTokenResponse, error) {
-	uid, err := s.db.getUIDByUsername(req.Username)
-	if err == errNotFound {
-		return nil, statusNotFound
-	} else if err != nil {
-		return nil, internalError(err)
-	}
-
-	samePassword, err := s.db.checkPassword(uid, req.Password)
-	if !samePassword {
-		return nil, status.Error(codes.Unauthenticated, "wrong password")
-	}
-
-	token := uuid.New().String()
-	err = s.refreshTokenStorage.Set(token, uid.String(), RefreshTokenExpirationTime).Err()
+	uid, err := s.authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	lifetimes := s.lifetimesFor(GrantPassword, nil)
+	token, err := s.issueRefreshToken(&tokenInfo{
+		UID:              uid.String(),
+		SubjectType:      SubjectTypeUser,
+		Family:           uuid.New().String(),
+		Scopes:           []string{ScopeFullAccess},
+		Grant:            GrantPassword,
+		SessionExpiresAt: lifetimes.sessionExpiresAt(),
+	}, lifetimes)
 	if err != nil {
 		return nil, internalError(err)
 	}
@@ -197,37 +311,58 @@ func (s *Server) GetRefreshToken(ctx context.Context, req *pb.GetTokenRequest) (
 // RefreshAccessToken returns new access and refresh tokens for user
 func (s *Server) RefreshAccessToken(ctx context.Context, req *pb.RefreshAccessTokenRequest) (*pb.RefreshAccessTokenResponse, error) {
 	token := req.RefreshToken
-	uid, err := s.refreshTokenStorage.Get(token).Result()
-	if err == redis.Nil {
+	info, err := s.getRefreshToken(token)
+	if err == errTokenNotFound {
 		return nil, statusInvalidUserToken
 	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	if _, err := uuid.Parse(uid); err != nil {
+	if _, err := uuid.Parse(info.UID); err != nil {
 		return nil, statusInvalidUserToken
 	}
 
-	err = s.refreshTokenStorage.Del(token).Err()
-	if err != nil {
+	lifetimes, err := s.appLifetimesFor(info.Grant, info.ClientApp)
+	if err == errNotFound {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	refreshToken := uuid.New().String()
-	err = s.refreshTokenStorage.Set(refreshToken, uid, RefreshTokenExpirationTime).Err()
+	rotated, err := s.rotateRefreshToken(token, info.Family, lifetimes.RefreshToken)
 	if err != nil {
 		return nil, internalError(err)
 	}
 
-	accessToken := uuid.New().String()
-	err = s.accessTokenStorage.Set(accessToken, uid, AccessTokenExpirationTime).Err()
-	if err != nil {
+	if !rotated {
+		// token was already exchanged, so either it or its successor has been stolen
+		if err := s.revokeFamily(info.Family); err != nil {
+			return nil, internalError(err)
+		}
+
+		s.emitSecurityEvent(SecurityEventRefreshTokenReuse, info.UID, "revoked token family "+info.Family)
+		return nil, statusInvalidUserToken
+	}
+
+	refreshToken, err := s.issueRefreshToken(info, lifetimes)
+	if err == errSessionExpired {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	accessToken, expiration, err := s.issueAccessToken(info, lifetimes)
+	if err == errSessionExpired {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
 		return nil, internalError(err)
 	}
 
+	tokensRefreshed.Inc()
 	res := new(pb.RefreshAccessTokenResponse)
 	res.RefreshToken = refreshToken
 	res.AccessToken = accessToken
+	res.ExpiresIn = int64(expiration.Seconds())
 	return res, nil
 }
 
@@ -238,14 +373,52 @@ func (s *Server) CreateApp(ctx context.Context, req *pb.CreateAppRequest) (*pb.C
 		return nil, statusInvalidUUID
 	}
 
-	app, err := s.db.createApp(owner, req.Name)
+	for _, redirectURI := range req.RedirectUris {
+		if !isValidRedirectURI(redirectURI) {
+			return nil, statusInvalidRedirect
+		}
+	}
+
+	for _, scope := range req.Scopes {
+		if !isValidScope(scope) {
+			return nil, statusInvalidScope
+		}
+	}
+
+	// apps can only shorten lifetimes configured on server, which also keeps them in range of database columns
+	requested := []struct {
+		name    string
+		seconds int64
+		limit   time.Duration
+	}{
+		{"access token lifetime", req.AccessTokenLifetime, s.lifetimes.AccessToken},
+		{"refresh token lifetime", req.RefreshTokenLifetime, s.lifetimes.RefreshToken},
+		{"session lifetime", req.SessionLifetime, s.lifetimes.Session},
+	}
+
+	for _, r := range requested {
+		if max := maxAppLifetime(r.limit); r.seconds < 0 || r.seconds > max {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be from 0 to %d seconds", r.name, max)
+		}
+	}
+
+	lifetimes := TokenLifetimes{
+		AccessToken:  time.Duration(req.AccessTokenLifetime) * time.Second,
+		RefreshToken: time.Duration(req.RefreshTokenLifetime) * time.Second,
+		Session:      time.Duration(req.SessionLifetime) * time.Second,
+	}
+
+	app, err := s.db.createApp(owner, req.Name, req.RedirectUris, req.IsPublic, req.Scopes, lifetimes)
 	if err != nil {
 		return nil, internalError(err)
 	}
 
 	resp := new(pb.CreateAppResponse)
 	resp.Id = app.UID.String()
-	resp.Secret = app.Secret.String()
+	// public apps can not keep secret confidential, so they use PKCE instead
+	if !app.IsPublic {
+		resp.Secret = app.Secret.String()
+	}
 
 	return resp, nil
 }
@@ -263,6 +436,7 @@ func (s *Server) GetAppInfo(ctx context.Context, req *pb.GetAppInfoRequest) (*pb
 		resp := new(pb.GetAppInfoResponse)
 		resp.Name = appInfo.Name
 		resp.Owner = appInfo.Owner.String()
+		resp.Scopes = appInfo.Scopes
 		return resp, nil
 	case errNotFound:
 		return nil, statusNotFound
@@ -271,29 +445,76 @@ func (s *Server) GetAppInfo(ctx context.Context, req *pb.GetAppInfoRequest) (*pb
 	}
 }
 
-// GetOAuthCode returns new oauth code
+// GetOAuthCode returns new oauth code, redirect uri must be registered by app
 func (s *Server) GetOAuthCode(ctx context.Context, req *pb.GetOAuthCodeRequest) (*pb.GetOAuthCodeResponse, error) {
-	uid, err := s.db.getUIDByUsername(req.Username)
+	appUID, err := uuid.Parse(req.AppUid)
+	if err != nil {
+		return nil, statusInvalidUUID
+	}
+
+	registered, err := s.db.isRegisteredRedirectURI(appUID, req.RedirectUri)
 	if err == errNotFound {
 		return nil, statusNotFound
 	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	samePassword, err := s.db.checkPassword(uid, req.Password)
+	if !registered {
+		return nil, statusInvalidRedirect
+	}
+
+	app, err := s.db.getAppInfo(appUID)
 	if err == errNotFound {
 		return nil, statusNotFound
 	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	if !samePassword {
-		return nil, status.Error(codes.Unauthenticated, "wrong password")
+	challengeMethod := req.CodeChallengeMethod
+	if challengeMethod == "" {
+		challengeMethod = codeChallengePlain
+	}
+
+	if req.CodeChallenge != "" && !isValidCodeChallenge(req.CodeChallenge, challengeMethod) {
+		return nil, statusInvalidChallenge
+	}
+
+	if req.CodeChallenge == "" && app.IsPublic {
+		return nil, status.Error(codes.InvalidArgument, "code challenge is required for public apps")
+	}
+
+	// app gets all scopes it is allowed to request when it does not ask for specific ones
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = app.Scopes
+	}
+
+	if !isSubset(scopes, app.Scopes) {
+		return nil, statusInvalidScope
+	}
+
+	if hasScope(scopes, ScopeOpenID) && s.signer == nil {
+		return nil, statusSigningDisabled
+	}
+
+	uid, err := s.authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, err
 	}
 
-	code := uuid.New().String()
+	codeInfo := &oauthCodeInfo{
+		UID:         uid.String(),
+		RedirectURI: req.RedirectUri,
+		Scopes:      scopes,
+		Nonce:       req.Nonce,
+		AuthTime:    time.Now().Unix(),
+	}
+	if req.CodeChallenge != "" {
+		codeInfo.CodeChallenge = req.CodeChallenge
+		codeInfo.CodeChallengeMethod = challengeMethod
+	}
 
-	err = s.oauthCodeStorage.Set(req.AppUid+code, uid.String(), time.Minute).Err()
+	code, err := s.issueOAuthCode(appUID.String(), codeInfo, s.lifetimesFor(GrantAuthorizationCode, app).OAuthCode)
 	if err != nil {
 		return nil, internalError(err)
 	}
@@ -304,56 +525,305 @@ func (s *Server) GetOAuthCode(ctx context.Context, req *pb.GetOAuthCodeRequest)
 	return resp, nil
 }
 
-// GetTokenFromCode returns access and refresh tokens for user by oauth code
+// GetTokenFromCode returns access and refresh tokens for user by oauth code,
+// public apps prove possession of the code with PKCE verifier instead of secret
 func (s *Server) GetTokenFromCode(ctx context.Context, req *pb.GetTokenFromCodeRequest) (*pb.GetTokenFromCodeResponse, error) {
-	appUID, err := uuid.Parse(req.AppUid)
+	appUID, app, err := s.authenticateApp(req.AppUid, req.AppSecret)
 	if err != nil {
-		return nil, statusInvalidUUID
+		return nil, err
+	}
+
+	code, err := s.consumeOAuthCode(appUID.String(), req.Code)
+	if err == errTokenNotFound {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
+		return nil, internalError(err)
+	}
+
+	if code.RedirectURI != req.RedirectUri {
+		return nil, statusInvalidRedirect
+	}
+
+	if code.CodeChallenge != "" && !code.verifyCodeChallenge(req.CodeVerifier) {
+		return nil, statusInvalidVerifier
+	}
+
+	lifetimes := s.lifetimesFor(GrantAuthorizationCode, app)
+	info := &tokenInfo{
+		UID:              code.UID,
+		SubjectType:      SubjectTypeUser,
+		ClientApp:        appUID.String(),
+		Family:           uuid.New().String(),
+		Scopes:           code.Scopes,
+		Grant:            GrantAuthorizationCode,
+		SessionExpiresAt: lifetimes.sessionExpiresAt(),
+	}
+	if info.Scopes == nil {
+		info.Scopes = []string{}
 	}
 
-	appSecret, err := uuid.Parse(req.AppSecret)
+	accessToken, expiration, err := s.issueAccessToken(info, lifetimes)
 	if err != nil {
-		return nil, statusInvalidUUID
+		return nil, internalError(err)
+	}
+
+	refreshToken, err := s.issueRefreshToken(info, lifetimes)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	resp := new(pb.GetTokenFromCodeResponse)
+	resp.AccessToken = accessToken
+	resp.RefreshToken = refreshToken
+	resp.ExpiresIn = int64(expiration.Seconds())
+
+	if hasScope(code.Scopes, ScopeOpenID) {
+		resp.IdToken, err = s.issueIDToken(appUID.String(), code, lifetimes.IDToken)
+		if err != nil {
+			return nil, internalError(err)
+		}
+	}
+
+	return resp, nil
+}
+
+// authenticateApp checks credentials of app, public apps are identified by uid alone since they can not keep secret
+func (s *Server) authenticateApp(appID, appSecret string) (uuid.UUID, *AppInfo, error) {
+	appUID, err := uuid.Parse(appID)
+	if err != nil {
+		return uuid.Nil, nil, statusInvalidUUID
 	}
 
-	valid, err := s.db.isValidAppCredentials(appUID, appSecret)
+	app, err := s.db.getAppInfo(appUID)
 	if err == errNotFound {
-		return nil, statusNotFound
+		return uuid.Nil, nil, statusNotFound
 	} else if err != nil {
-		return nil, internalError(err)
+		return uuid.Nil, nil, internalError(err)
 	}
 
-	if !valid {
-		return nil, status.Error(codes.Unauthenticated, "wrong appid appsecret pair")
+	if app.IsPublic {
+		return appUID, app, nil
 	}
 
-	uid, err := s.oauthCodeStorage.Get(req.AppUid + req.Code).Result()
-	if err == redis.Nil {
-		return nil, statusInvalidUserToken
+	secret, err := uuid.Parse(appSecret)
+	if err != nil {
+		return uuid.Nil, nil, statusInvalidUUID
+	}
+
+	valid, err := s.db.isValidAppCredentials(appUID, secret)
+	if err == errNotFound {
+		return uuid.Nil, nil, statusNotFound
 	} else if err != nil {
+		return uuid.Nil, nil, internalError(err)
+	}
+
+	if !valid {
+		return uuid.Nil, nil, statusInvalidAppCredentials
+	}
+
+	return appUID, app, nil
+}
+
+// GetClientCredentialsToken returns access token issued to app itself as described in RFC 6749 section 4.4,
+// only confidential apps can use it
+func (s *Server) GetClientCredentialsToken(ctx context.Context, req *pb.GetClientCredentialsTokenRequest) (*pb.GetClientCredentialsTokenResponse, error) {
+	appUID, app, err := s.authenticateApp(req.AppUid, req.AppSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// public app is identified by uid alone, so anyone could get its token
+	if app.IsPublic {
+		return nil, statusUnauthorizedClient
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = app.Scopes
+	}
+
+	// user claims can not be granted to token without user
+	if !isSubset(scopes, app.Scopes) || hasScope(scopes, ScopeOpenID) {
+		return nil, statusInvalidScope
+	}
+
+	lifetimes := s.lifetimesFor(GrantClientCredentials, app)
+	token, expiration, err := s.issueAccessToken(&tokenInfo{
+		UID:              appUID.String(),
+		SubjectType:      SubjectTypeApp,
+		ClientApp:        appUID.String(),
+		Scopes:           scopes,
+		Grant:            GrantClientCredentials,
+		SessionExpiresAt: lifetimes.sessionExpiresAt(),
+	}, lifetimes)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	resp := new(pb.GetClientCredentialsTokenResponse)
+	resp.AccessToken = token
+	resp.ExpiresIn = int64(expiration.Seconds())
+
+	return resp, nil
+}
+
+// GetPublicKeys returns JWKS document with keys used to sign access tokens
+func (s *Server) GetPublicKeys(ctx context.Context, req *pb.GetPublicKeysRequest) (*pb.GetPublicKeysResponse, error) {
+	if s.signer == nil {
+		return nil, statusSigningDisabled
+	}
+
+	jwks, err := s.signer.JWKS()
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	resp := new(pb.GetPublicKeysResponse)
+	resp.Jwks = string(jwks)
+
+	return resp, nil
+}
+
+// RotateSigningKey makes a new key active for signing access tokens, caller must be admin
+func (s *Server) RotateSigningKey(ctx context.Context, req *pb.RotateSigningKeyRequest) (*pb.RotateSigningKeyResponse, error) {
+	ownerUID, err := s.accessTokenOwner(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAdmin(ownerUID); err != nil {
+		return nil, err
+	}
+
+	if s.signer == nil {
+		return nil, statusSigningDisabled
+	}
+
+	kid, err := s.signer.Rotate()
+	if err != nil {
 		return nil, internalError(err)
 	}
 
-	err = s.oauthCodeStorage.Del(req.AppUid + req.Code).Err()
-	if err != nil && err != redis.Nil {
+	resp := new(pb.RotateSigningKeyResponse)
+	resp.Kid = kid
+
+	return resp, nil
+}
+
+// RevokeToken revokes access or refresh token, unknown tokens are ignored as described in RFC 7009
+func (s *Server) RevokeToken(ctx context.Context, req *pb.RevokeTokenRequest) (*pb.RevokeTokenResponse, error) {
+	revokers := []func(string) (bool, error){s.revokeAccessToken, s.revokeRefreshToken}
+	if req.TokenTypeHint == refreshTokenTypeHint {
+		revokers[0], revokers[1] = revokers[1], revokers[0]
+	}
+
+	for _, revoke := range revokers {
+		revoked, err := revoke(req.Token)
+		if err != nil {
+			return nil, internalError(err)
+		}
+
+		if revoked {
+			break
+		}
+	}
+
+	return new(pb.RevokeTokenResponse), nil
+}
+
+// IntrospectToken returns metadata of access or refresh token as described in RFC 7662, it does not change token.
+// Unknown, expired and revoked tokens are reported as inactive
+func (s *Server) IntrospectToken(ctx context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectTokenResponse, error) {
+	lookups := []struct {
+		tokenType string
+		lookup    func(string) (*tokenInfo, time.Duration, error)
+	}{
+		{accessTokenTypeHint, s.lookupAccessToken},
+		{refreshTokenTypeHint, s.lookupRefreshToken},
+	}
+	if req.TokenTypeHint == refreshTokenTypeHint {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+
+	resp := new(pb.IntrospectTokenResponse)
+	for _, l := range lookups {
+		info, ttl, err := l.lookup(req.Token)
+		if err == errTokenNotFound {
+			continue
+		} else if err != nil {
+			return nil, internalError(err)
+		}
+
+		resp.Active = true
+		resp.Subject = info.UID
+		resp.SubjectType = info.SubjectType
+		resp.ClientApp = info.ClientApp
+		resp.Scopes = info.Scopes
+		resp.IssuedAt = info.IssuedAt
+		resp.TokenType = l.tokenType
+		if ttl > 0 {
+			resp.ExpiresAt = time.Now().Add(ttl).Unix()
+		}
+
+		break
+	}
+
+	return resp, nil
+}
+
+// LogoutEverywhere revokes all access and refresh tokens of user
+func (s *Server) LogoutEverywhere(ctx context.Context, req *pb.LogoutEverywhereRequest) (*pb.LogoutEverywhereResponse, error) {
+	uid, err := uuid.Parse(req.Uid)
+	if err != nil {
+		return nil, statusInvalidUUID
+	}
+
+	if err := s.checkPermission(req.UserToken, uid); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeUserTokens(uid.String()); err != nil {
 		return nil, internalError(err)
 	}
 
-	accessToken := uuid.New().String()
-	err = s.accessTokenStorage.Set(accessToken, uid, AccessTokenExpirationTime).Err()
+	return new(pb.LogoutEverywhereResponse), nil
+}
+
+// GetUserClaims returns claims about owner of access token allowed by scopes granted to the token
+func (s *Server) GetUserClaims(ctx context.Context, req *pb.GetUserClaimsRequest) (*pb.GetUserClaimsResponse, error) {
+	tokenID, err := s.accessTokenID(req.UserToken)
 	if err != nil {
+		return nil, statusInvalidUserToken
+	}
+
+	info, err := s.getAccessToken(tokenID)
+	if err == errTokenNotFound {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	refreshToken := uuid.New().String()
-	err = s.refreshTokenStorage.Set(refreshToken, uid, RefreshTokenExpirationTime).Err()
+	if info.SubjectType != SubjectTypeUser || !hasScope(info.Scopes, ScopeOpenID) {
+		return nil, statusInsufficientScope
+	}
+
+	uid, err := uuid.Parse(info.UID)
 	if err != nil {
+		return nil, statusInvalidUserToken
+	}
+
+	user, err := s.db.getUserInfo(uid)
+	if err == errNotFound {
+		return nil, statusInvalidUserToken
+	} else if err != nil {
 		return nil, internalError(err)
 	}
 
-	resp := new(pb.GetTokenFromCodeResponse)
-	resp.AccessToken = accessToken
-	resp.RefreshToken = refreshToken
+	resp := new(pb.GetUserClaimsResponse)
+	resp.Sub = user.UID.String()
+	if hasScope(info.Scopes, ScopeProfile) {
+		resp.PreferredUsername = user.Username
+	}
 
 	return resp, nil
 }
@@ -0,0 +1,338 @@
+package user
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	pb "github.com/andreymgn/RSOI-user/pkg/user/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestModifyUserPermission(t *testing.T) {
+	rpcs := []struct {
+		name string
+		call func(s *Server, token string, uid uuid.UUID) error
+	}{
+		{"UpdateUser", func(s *Server, token string, uid uuid.UUID) error {
+			_, err := s.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+				Uid:       uid.String(),
+				UserToken: token,
+				Password:  "battery staple",
+			})
+			return err
+		}},
+		{"DeleteUser", func(s *Server, token string, uid uuid.UUID) error {
+			_, err := s.DeleteUser(context.Background(), &pb.DeleteUserRequest{Uid: uid.String(), UserToken: token})
+			return err
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		caller string
+		scopes []string
+		target string
+		want   error
+	}{
+		{"self", "owner", []string{ScopeFullAccess}, "owner", nil},
+		{"other user", "other", []string{ScopeFullAccess}, "owner", statusForbidden},
+		{"admin", "admin", []string{ScopeFullAccess}, "owner", nil},
+		{"admin modifies self", "admin", []string{ScopeFullAccess}, "admin", nil},
+		{"scoped oauth token of owner", "owner", []string{ScopeOpenID, ScopeProfile}, "owner", statusInsufficientScope},
+		{"scoped oauth token of admin", "admin", []string{ScopeOpenID, ScopeProfile}, "owner", statusInsufficientScope},
+		{"unknown token", "", nil, "owner", statusInvalidUserToken},
+		{"deleted user", "deleted", []string{ScopeFullAccess}, "owner", statusInvalidUserToken},
+	}
+
+	for _, rpc := range rpcs {
+		for _, tt := range tests {
+			t.Run(rpc.name+"/"+tt.name, func(t *testing.T) {
+				db := newFakeDB()
+				s := newTestServer(t, db)
+				users := map[string]uuid.UUID{
+					"owner":   addUser(t, s, db, "owner", "correct horse", false),
+					"other":   addUser(t, s, db, "other", "correct horse", false),
+					"admin":   addUser(t, s, db, "admin", "correct horse", true),
+					"deleted": addUser(t, s, db, "deleted", "correct horse", true),
+				}
+
+				token := uuid.New().String()
+				if tt.caller != "" {
+					token = issueUserToken(t, s, users[tt.caller], tt.scopes...)
+				}
+
+				delete(db.users, users["deleted"])
+
+				target := users[tt.target]
+				hash, err := db.getPasswordHash(target)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := rpc.call(s, token, target); err != tt.want {
+					t.Fatalf("%s() = %v, want %v", rpc.name, err, tt.want)
+				}
+
+				newHash, err := db.getPasswordHash(target)
+				if modified := err != nil || newHash != hash; modified != (tt.want == nil) {
+					t.Errorf("user modified = %v, want %v", modified, tt.want == nil)
+				}
+			})
+		}
+	}
+}
+
+func TestCheckAdmin(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	user := addUser(t, s, db, "user", "password", false)
+	admin := addUser(t, s, db, "admin", "password", true)
+
+	tests := []struct {
+		name  string
+		uid   uuid.UUID
+		dbErr error
+		want  codes.Code
+	}{
+		{"admin", admin, nil, codes.OK},
+		{"user", user, nil, codes.PermissionDenied},
+		{"unknown user", uuid.New(), nil, codes.Unauthenticated},
+		{"database error", admin, errFakeDB, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db.err = tt.dbErr
+			defer func() { db.err = nil }()
+
+			if got := status.Code(s.checkAdmin(tt.uid)); got != tt.want {
+				t.Errorf("checkAdmin() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "user", "password", false)
+	ctx := context.Background()
+
+	token, err := s.issueRefreshToken(&tokenInfo{
+		UID:         uid.String(),
+		SubjectType: SubjectTypeUser,
+		Family:      uuid.New().String(),
+		Scopes:      []string{ScopeFullAccess},
+		Grant:       GrantPassword,
+	}, s.lifetimes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := s.RefreshAccessToken(ctx, &pb.RefreshAccessTokenRequest{RefreshToken: token})
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+
+	if _, err := s.RefreshAccessToken(ctx, &pb.RefreshAccessTokenRequest{RefreshToken: token}); err != statusInvalidUserToken {
+		t.Fatalf("reused refresh token: got %v, want %v", err, statusInvalidUserToken)
+	}
+
+	// reuse revokes the whole family, including tokens issued to whoever exchanged token first
+	if _, err := s.RefreshAccessToken(ctx, &pb.RefreshAccessTokenRequest{RefreshToken: refreshed.RefreshToken}); err != statusInvalidUserToken {
+		t.Errorf("successor of reused token: got %v, want %v", err, statusInvalidUserToken)
+	}
+
+	_, err = s.GetUserByAccessToken(ctx, &pb.GetUserByAccessTokenRequest{UserToken: refreshed.AccessToken})
+	if err != statusInvalidUserToken {
+		t.Errorf("access token of revoked family: got %v, want %v", err, statusInvalidUserToken)
+	}
+}
+
+func TestRefreshAccessTokenRejectsNonTokenKeys(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "user", "password", false)
+	family := uuid.New().String()
+
+	token, err := s.issueRefreshToken(&tokenInfo{
+		UID:         uid.String(),
+		SubjectType: SubjectTypeUser,
+		Family:      family,
+		Scopes:      []string{ScopeOpenID},
+		Grant:       GrantPassword,
+	}, s.lifetimes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RefreshAccessToken(context.Background(), &pb.RefreshAccessTokenRequest{RefreshToken: token}); err != nil {
+		t.Fatal(err)
+	}
+
+	// marker holding uid of existing user would pass as a legacy token if it was looked up
+	legacyMarker := rotatedMarker(uuid.New().String())
+	if err := s.refreshTokenStorage.Set(legacyMarker, uid.String(), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"rotation marker", rotatedMarker(token)},
+		{"marker with uid", legacyMarker},
+		{"user index", userTokensIndex(uid.String())},
+		{"family index", familyIndex(family)},
+		{"uppercase token", "A" + token[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RefreshAccessToken(context.Background(), &pb.RefreshAccessTokenRequest{RefreshToken: tt.key})
+			if err != statusInvalidUserToken {
+				t.Errorf("RefreshAccessToken(%q) = %v, want %v", tt.key, err, statusInvalidUserToken)
+			}
+		})
+	}
+}
+
+func TestParseTokenInfo(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "user", "password", false)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantUID string
+		wantErr error
+	}{
+		{"json", `{"uid":"` + uid.String() + `","scopes":["openid"]}`, uid.String(), nil},
+		{"legacy uid of existing user", uid.String(), uid.String(), nil},
+		{"legacy uid of unknown user", uuid.New().String(), "", errTokenNotFound},
+		{"rotated token", `{"family":"f","rotated_at":1}`, "", errTokenNotFound},
+		{"raw token", "not a uid", "", errTokenNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := s.parseTokenInfo(tt.value)
+			if err != tt.wantErr {
+				t.Fatalf("parseTokenInfo() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && info.UID != tt.wantUID {
+				t.Errorf("parseTokenInfo() uid = %q, want %q", info.UID, tt.wantUID)
+			}
+		})
+	}
+}
+
+func TestGetUserByAccessTokenExtension(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	uid := addUser(t, s, db, "user", "password", false)
+	token := issueUserToken(t, s, uid, ScopeFullAccess)
+
+	req := &pb.GetUserByAccessTokenRequest{UserToken: token, ExtendExpiration: true}
+	if _, err := s.GetUserByAccessToken(context.Background(), req); err != nil {
+		t.Errorf("extension of stored token: %v", err)
+	}
+
+	s.signer = new(JWTSigner)
+	if _, err := s.GetUserByAccessToken(context.Background(), req); err != statusCannotExtend {
+		t.Errorf("extension with signing enabled: got %v, want %v", err, statusCannotExtend)
+	}
+}
+
+func TestGetClientCredentialsTokenChecks(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	confidential := addApp(t, db, testRedirectURI, false, []string{ScopeProfile})
+	public := addApp(t, db, testRedirectURI, true, []string{ScopeProfile})
+
+	tests := []struct {
+		name   string
+		app    string
+		secret string
+		scopes []string
+		want   error
+	}{
+		{"confidential app", confidential.UID.String(), confidential.Secret.String(), nil, nil},
+		{"wrong secret", confidential.UID.String(), uuid.New().String(), nil, statusInvalidAppCredentials},
+		{"invalid secret", confidential.UID.String(), "secret", nil, statusInvalidUUID},
+		{"unknown app", uuid.New().String(), uuid.New().String(), nil, statusNotFound},
+		{"public app", public.UID.String(), "", nil, statusUnauthorizedClient},
+		{"public app with secret", public.UID.String(), public.Secret.String(), nil, statusUnauthorizedClient},
+		{"user claims", confidential.UID.String(), confidential.Secret.String(), []string{ScopeOpenID}, statusInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &pb.GetClientCredentialsTokenRequest{AppUid: tt.app, AppSecret: tt.secret, Scopes: tt.scopes}
+			if _, err := s.GetClientCredentialsToken(context.Background(), req); err != tt.want {
+				t.Errorf("GetClientCredentialsToken() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAppLifetimes(t *testing.T) {
+	db := newFakeDB()
+	s := newTestServer(t, db)
+	accessLimit := int64(s.lifetimes.AccessToken / time.Second)
+
+	tests := []struct {
+		name    string
+		access  int64
+		refresh int64
+		session int64
+		want    codes.Code
+	}{
+		{"server lifetimes", 0, 0, 0, codes.OK},
+		{"shorter lifetimes", 60, 3600, 7200, codes.OK},
+		{"server limit", accessLimit, 0, 0, codes.OK},
+		{"negative", -1, 0, 0, codes.InvalidArgument},
+		{"above server limit", accessLimit + 1, 0, 0, codes.InvalidArgument},
+		{"refresh token above server limit", 0, 1 << 31, 0, codes.InvalidArgument},
+		{"session overflowing duration", 0, 0, 1 << 62, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateApp(context.Background(), &pb.CreateAppRequest{
+				Owner:                uuid.New().String(),
+				Name:                 "app",
+				AccessTokenLifetime:  tt.access,
+				RefreshTokenLifetime: tt.refresh,
+				SessionLifetime:      tt.session,
+			})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("CreateApp() code = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMaxAppLifetime(t *testing.T) {
+	tests := []struct {
+		limit time.Duration
+		want  int64
+	}{
+		{time.Minute, 60},
+		{1500 * time.Millisecond, 1},
+		{0, math.MaxInt32},
+		{100 * 365 * 24 * time.Hour, math.MaxInt32},
+	}
+
+	for _, tt := range tests {
+		if got := maxAppLifetime(tt.limit); got != tt.want {
+			t.Errorf("maxAppLifetime(%v) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/secure/precis"
+)
+
+const (
+	// MinUsernameLength is a minimal number of characters in username
+	MinUsernameLength = 3
+	// MaxUsernameLength is a maximal number of characters in username, it matches users.username column
+	MaxUsernameLength = 30
+)
+
+var (
+	errInvalidUsername = errors.New("invalid username")
+)
+
+// reservedUsernames can not be registered because they may be mistaken for service accounts, stored normalized
+var reservedUsernames = map[string]bool{
+	"admin":         true,
+	"administrator": true,
+	"root":          true,
+	"system":        true,
+	"superuser":     true,
+	"support":       true,
+	"security":      true,
+	"moderator":     true,
+	"service":       true,
+	"api":           true,
+	"oauth":         true,
+	"me":            true,
+	"null":          true,
+	"anonymous":     true,
+	"guest":         true,
+}
+
+// normalizeUsername returns form of username used to check uniqueness and to look users up. It maps width
+// and case and applies Unicode normalization as described in RFC 8265 UsernameCaseMapped profile
+func normalizeUsername(username string) (string, error) {
+	normalized, err := precis.UsernameCaseMapped.String(username)
+	if err != nil {
+		return "", errInvalidUsername
+	}
+
+	return normalized, nil
+}
+
+// isUsernameLetter checks whether r is a basic Latin letter or a Latin letter with diacritic from Latin-1 Supplement
+// and Latin Extended-A blocks. Letters of other scripts are rejected since many of them look exactly like Latin
+// ones, so "аdmin" with Cyrillic "а" would pass for reserved or existing username
+func isUsernameLetter(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return true
+	case r == 'ı', r == 'ĸ', r == 'ſ', r == 'Ŀ', r == 'ŀ':
+		// dotless i, kra and long s pass for i, k and f, middle dot looks like '.'
+		return false
+	}
+
+	return r >= 0xC0 && r <= 0x17F && unicode.IsLetter(r)
+}
+
+// isUsernameAlnum checks whether r is an allowed letter or an ASCII digit
+func isUsernameAlnum(r rune) bool {
+	return isUsernameLetter(r) || r >= '0' && r <= '9'
+}
+
+// isUsernameChar checks whether r is a letter, a digit or one of separators allowed in usernames
+func isUsernameChar(r rune) bool {
+	return isUsernameAlnum(r) || r == '_' || r == '.' || r == '-'
+}
+
+// usernameViolations returns descriptions of all rules username violates
+func usernameViolations(username string) []string {
+	if username == "" {
+		return []string{"username is empty"}
+	}
+
+	var result []string
+	validLength := true
+	if n := utf8.RuneCountInString(username); n < MinUsernameLength || n > MaxUsernameLength {
+		validLength = false
+		result = append(result, fmt.Sprintf("username must be from %d to %d characters long", MinUsernameLength, MaxUsernameLength))
+	}
+
+	validChars := true
+	for _, r := range username {
+		if !isUsernameChar(r) {
+			validChars = false
+			result = append(result, "username may contain only Latin letters, digits, '_', '.' and '-'")
+			break
+		}
+	}
+
+	if first, _ := utf8.DecodeRuneInString(username); !isUsernameAlnum(first) {
+		result = append(result, "username must start with a letter or a digit")
+	}
+
+	if !validChars {
+		return result
+	}
+
+	normalized, err := normalizeUsername(username)
+	switch {
+	case err != nil:
+		result = append(result, "username contains characters which can not be normalized")
+	case validLength && utf8.RuneCountInString(normalized) > MaxUsernameLength:
+		result = append(result, fmt.Sprintf("normalized username must be at most %d characters long", MaxUsernameLength))
+	case reservedUsernames[normalized]:
+		result = append(result, "username is reserved")
+	}
+
+	return result
+}
+
+// checkUsername returns InvalidArgument status listing violated rules in BadRequest details or nil if username
+// is acceptable
+func checkUsername(username string) error {
+	violations := usernameViolations(username)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return invalidArgument("username", "username is invalid", violations)
+}
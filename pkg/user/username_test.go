@@ -0,0 +1,54 @@
+package user
+
+import "testing"
+
+func TestUsernameViolations(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		valid    bool
+	}{
+		{"latin", "alice", true},
+		{"digits and separators", "alice_1.b-2", true},
+		{"letters with diacritics", "zoë_łukasz", true},
+		{"other script", "алиса", false},
+		{"reserved with cyrillic letter", "аdmin", false},
+		{"reserved with greek letter", "αdmin", false},
+		{"dotless i", "admın", false},
+		{"non-ascii digits", "alice٣", false},
+		{"empty", "", false},
+		{"too short", "al", false},
+		{"too long", "alice_alice_alice_alice_alice_1", false},
+		{"space", "alice smith", false},
+		{"starts with separator", ".alice", false},
+		{"reserved", "admin", false},
+		{"reserved in other case", "Admin", false},
+		{"full width", "ａｌｉｃｅ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := usernameViolations(tt.username)
+			if valid := len(violations) == 0; valid != tt.valid {
+				t.Errorf("usernameViolations(%q) = %q, want valid = %v", tt.username, violations, tt.valid)
+			}
+		})
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{"alice", "alice"},
+		{"ALICE", "alice"},
+		{"ＢＯＢ", "bob"},
+	}
+
+	for _, tt := range tests {
+		if got, err := normalizeUsername(tt.username); err != nil || got != tt.want {
+			t.Errorf("normalizeUsername(%q) = %q, %v, want %q", tt.username, got, err, tt.want)
+		}
+	}
+}